@@ -0,0 +1,165 @@
+// cmd/village-watch/export.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/export"
+	"example.com/village-watch/internal/git"
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scan"
+	"example.com/village-watch/internal/scene"
+	"example.com/village-watch/internal/snapshot"
+)
+
+const exportUsage = `usage: village-watch export <format> [flags]
+
+formats:
+  png    bitmap image drawn with the bundled font
+  svg    vector image with the file path of every building as a tooltip
+  html   self-contained page; hover a building for its path, click to copy it
+  json   snapshot of the tree and its layout, for other tools and --from-snapshot
+
+flags:
+`
+
+// runExport renders one scene of a directory to a file, without a terminal
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), exportUsage)
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	format, args := args[0], args[1:]
+
+	var path, theme, ignoreExtra, out string
+	var noUnicode, full, transparent bool
+	var cols, rows, scale int
+	fs.StringVar(&path, "path", ".", "directory to visualize")
+	fs.StringVar(&theme, "theme", "forest", "theme name (bundled: contrast|desert|forest|seaside, or a user theme)")
+	fs.BoolVar(&noUnicode, "no-unicode", false, "use ASCII-only tiles")
+	fs.StringVar(&ignoreExtra, "ignore", "", "comma-separated ignore globs")
+	fs.StringVar(&out, "out", "", "output file, - for stdout (default village.<format>)")
+	fs.BoolVar(&full, "full", false, "export the whole virtual map instead of the viewport")
+	fs.IntVar(&cols, "cols", 120, "viewport width in cells")
+	fs.IntVar(&rows, "rows", 40, "viewport height in cells")
+	fs.IntVar(&scale, "scale", 2, "png: pixels per font pixel")
+	fs.BoolVar(&transparent, "transparent", false, "leave the background transparent")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	write, ok := exporters[format]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	cfg, _ := config.Load(abs) // best-effort load village.yml
+	cfg.Theme = theme
+	cfg.Render.Unicode = !noUnicode
+	cfg.ApplyIgnoreCSV(ignoreExtra)
+
+	repo, sc, err := exportScene(abs, cfg, cols, rows, full)
+	if err != nil {
+		return err
+	}
+	themeDir := cfg.ThemesDir
+	if themeDir == "" {
+		themeDir = render.DefaultThemeDir()
+	}
+	themes, err := render.LoadThemes(themeDir)
+	if err != nil {
+		return fmt.Errorf("themes: %w", err)
+	}
+	t := themes.Get(cfg.Theme)
+
+	if out == "" {
+		out = "village." + format
+	}
+	return writeOutput(out, func(w io.Writer) error {
+		return write(w, sc, t, exportOptions{repo: repo, root: abs, scale: scale, full: full, transparent: transparent})
+	})
+}
+
+type exportOptions struct {
+	repo              *domain.RepoState // the tree the scene shows
+	root              string
+	scale             int
+	full, transparent bool
+}
+
+// exporters write a scene in each format
+var exporters = map[string]func(io.Writer, scene.Scene, render.Theme, exportOptions) error{
+	"png": func(w io.Writer, sc scene.Scene, t render.Theme, o exportOptions) error {
+		return export.PNG(w, sc, t, export.PNGOptions{Scale: o.scale, Full: o.full, Transparent: o.transparent})
+	},
+	"svg": func(w io.Writer, sc scene.Scene, t render.Theme, o exportOptions) error {
+		return export.SVG(w, sc, t, export.DocOptions{Full: o.full, Transparent: o.transparent, Root: o.root})
+	},
+	"html": func(w io.Writer, sc scene.Scene, t render.Theme, o exportOptions) error {
+		return export.HTML(w, sc, t, export.DocOptions{Full: o.full, Transparent: o.transparent, Root: o.root})
+	},
+	"json": func(w io.Writer, sc scene.Scene, _ render.Theme, o exportOptions) error {
+		return snapshot.Write(w, snapshot.Take(o.repo, &sc))
+	},
+}
+
+// exportScene scans root and derives its scene the way the TUI would show
+// it in a cols x rows terminal, git flags included
+func exportScene(root string, cfg config.Config, cols, rows int, full bool) (*domain.RepoState, scene.Scene, error) {
+	renderer, err := buildings.NewRendererFromConfig(root, cfg)
+	if err != nil {
+		return nil, scene.Scene{}, fmt.Errorf("config: %w", err)
+	}
+	repo, err := scan.BuildTree(root, cfg)
+	if err != nil {
+		return nil, scene.Scene{}, fmt.Errorf("scanning directory: %w", err)
+	}
+	if cfg.Git.Enabled {
+		states, err := git.Read(root)
+		if err != nil && !errors.Is(err, git.ErrNotRepo) {
+			return nil, scene.Scene{}, fmt.Errorf("git: %w", err)
+		}
+		repo.ApplyGit(states)
+	}
+	bounds := scene.BoundsFromConfig(cfg.Render.Map)
+	if full {
+		// a viewport covering the whole map
+		cols, rows = scene.MapSize(repo.Root, 0, 0, bounds)
+	}
+	return repo, scene.DeriveWithOptions(repo, max(1, cols), max(1, rows), scene.Options{
+		Unicode: cfg.Render.Unicode, Renderer: renderer, Bounds: &bounds,
+	}), nil
+}
+
+// writeOutput writes to the named file, or to stdout for "-"
+func writeOutput(name string, write func(io.Writer) error) error {
+	if name == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
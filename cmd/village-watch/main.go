@@ -2,6 +2,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -10,27 +11,62 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"example.com/village-watch/internal/buildings"
 	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/recording"
 	"example.com/village-watch/internal/scan"
 	"example.com/village-watch/internal/scene"
+	"example.com/village-watch/internal/snapshot"
+	"example.com/village-watch/internal/sshd"
 	"example.com/village-watch/internal/ui"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Println("export error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var path string
 	var fps int
 	var theme string
 	var noUnicode bool
 	var ignoreExtra string
 	var testLayout bool
+	var timelapse bool
+	var serveAddr string
+	var sshOpts sshd.Options
+	var fromSnapshot string
+	var recordFile, replayFile string
+	var replaySpeed float64
 
 	flag.StringVar(&path, "path", ".", "directory to visualize")
 	flag.IntVar(&fps, "fps", 20, "target frames per second")
-	flag.StringVar(&theme, "theme", "forest", "theme: forest|seaside|desert|contrast")
+	flag.StringVar(&theme, "theme", "forest", "theme name (bundled: contrast|desert|forest|seaside, or a user theme)")
 	flag.BoolVar(&noUnicode, "no-unicode", false, "use ASCII-only tiles")
 	flag.StringVar(&ignoreExtra, "ignore", "", "comma-separated ignore globs")
 	flag.BoolVar(&testLayout, "test", false, "test layout generation and print to console")
+	flag.BoolVar(&timelapse, "timelapse", false, "replay the git history from the first commit to HEAD")
+	flag.StringVar(&serveAddr, "serve", "", "serve a live web view on this address (e.g. :8080) instead of the TUI")
+	flag.StringVar(&sshOpts.Addr, "ssh", "", "serve the TUI over SSH on this address (e.g. :2222) to the authorized keys")
+	flag.StringVar(&sshOpts.HostKey, "host-key", sshd.DefaultHostKey(), "ssh: private host key, generated when missing")
+	flag.StringVar(&sshOpts.AuthorizedKeys, "authorized-keys", sshd.DefaultAuthorizedKeys(), "ssh: public keys allowed to connect")
+	flag.StringVar(&fromSnapshot, "from-snapshot", "", "show a village saved by export json instead of scanning --path")
+	flag.StringVar(&recordFile, "record", "", "write the watcher events to this session file while watching")
+	flag.StringVar(&replayFile, "replay", "", "play a session written by --record instead of watching --path")
+	flag.Float64Var(&replaySpeed, "replay-speed", 1, "replay: multiple of the recorded pace")
 	flag.Parse()
+	if err := checkModes(timelapse, fromSnapshot, recordFile, replayFile); err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
 
 	abs, err := filepath.Abs(path)
 	if err != nil {
@@ -46,7 +82,7 @@ func main() {
 
 	// Test layout mode - print village layout to console
 	if testLayout {
-		err := testVillageLayout(abs, &cfg)
+		err := testVillageLayout(abs, &cfg, fromSnapshot)
 		if err != nil {
 			fmt.Println("test error:", err)
 			os.Exit(1)
@@ -54,7 +90,50 @@ func main() {
 		return
 	}
 
-	m, err := ui.NewModel(abs, cfg)
+	if serveAddr != "" {
+		if err := serveVillage(abs, cfg, serveAddr); err != nil {
+			fmt.Println("serve error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if sshOpts.Addr != "" {
+		if err := sshVillage(abs, cfg, sshOpts); err != nil {
+			fmt.Println("ssh error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	newModel := ui.NewModel
+	if timelapse {
+		newModel = ui.NewTimelapseModel
+	}
+	if fromSnapshot != "" {
+		newModel = func(root string, cfg config.Config) (ui.Model, error) {
+			repo, bounds, err := villageRepo(root, cfg, fromSnapshot)
+			if err != nil {
+				return ui.Model{}, err
+			}
+			return ui.NewStillModel(root, cfg, repo, bounds)
+		}
+	}
+	if recordFile != "" {
+		newModel = func(root string, cfg config.Config) (ui.Model, error) {
+			return ui.NewRecordingModel(root, cfg, recordFile)
+		}
+	}
+	if replayFile != "" {
+		newModel = func(root string, cfg config.Config) (ui.Model, error) {
+			session, err := recording.Load(replayFile)
+			if err != nil {
+				return ui.Model{}, err
+			}
+			return ui.NewReplayModel(root, cfg, session, replaySpeed)
+		}
+	}
+	m, err := newModel(abs, cfg)
 	if err != nil {
 		fmt.Println("init error:", err)
 		os.Exit(1)
@@ -67,23 +146,42 @@ func main() {
 	}
 }
 
+// checkModes rejects combining the flags that each choose what the TUI shows
+// instead of the live village, as only one of them could take effect
+func checkModes(timelapse bool, fromSnapshot, recordFile, replayFile string) error {
+	var set []string
+	for _, m := range []struct {
+		flag string
+		on   bool
+	}{
+		{"--timelapse", timelapse},
+		{"--from-snapshot", fromSnapshot != ""},
+		{"--record", recordFile != ""},
+		{"--replay", replayFile != ""},
+	} {
+		if m.on {
+			set = append(set, m.flag)
+		}
+	}
+	if len(set) > 1 {
+		return fmt.Errorf("%s cannot be combined", strings.Join(set, " and "))
+	}
+	return nil
+}
+
 // testVillageLayout generates and prints the village layout to console
-func testVillageLayout(path string, cfg *config.Config) error {
+func testVillageLayout(path string, cfg *config.Config, snapshotFile string) error {
 	fmt.Printf("=== Village Layout Test ===\n")
 	fmt.Printf("Path: %s\n", path)
-	fmt.Printf("Virtual Map Size: %dx%d\n", scene.VirtualMapWidth, scene.VirtualMapHeight)
-	fmt.Printf("Unicode: %v\n", cfg.Render.Unicode)
-	fmt.Printf("Theme: %s\n", cfg.Theme)
-	fmt.Printf("\n")
-
-	// Scan directory using the correct function
-	repo, err := scan.BuildTree(path, *cfg)
+	repo, sc, err := testScene(path, *cfg, snapshotFile)
 	if err != nil {
-		return fmt.Errorf("scanning directory: %w", err)
+		return err
 	}
 
-	// Generate scene using virtual map dimensions
-	sc := scene.Derive(repo, scene.VirtualMapWidth, scene.VirtualMapHeight, cfg.Render.Unicode)
+	fmt.Printf("Virtual Map Size: %dx%d\n", sc.MapW, sc.MapH)
+	fmt.Printf("Unicode: %v\n", cfg.Render.Unicode)
+	fmt.Printf("Theme: %s\n", cfg.Theme)
+	fmt.Printf("\n")
 
 	fmt.Printf("Generated village with %d buildings:\n", len(repo.Index)-1)
 	
@@ -112,3 +210,44 @@ func testVillageLayout(path string, cfg *config.Config) error {
 
 	return nil
 }
+
+// testScene derives the scene --test prints, with a viewport covering the
+// whole virtual map
+func testScene(path string, cfg config.Config, snapshotFile string) (*domain.RepoState, scene.Scene, error) {
+	renderer, err := buildings.NewRendererFromConfig(path, cfg)
+	if err != nil {
+		return nil, scene.Scene{}, fmt.Errorf("config: %w", err)
+	}
+	// Scan the directory, or load the snapshot in its place
+	repo, bounds, err := villageRepo(path, cfg, snapshotFile)
+	if err != nil {
+		return nil, scene.Scene{}, err
+	}
+	mapW, mapH := scene.MapSize(repo.Root, 0, 0, bounds)
+	return repo, scene.DeriveWithOptions(repo, mapW, mapH, scene.Options{
+		Unicode: cfg.Render.Unicode, Renderer: renderer, Bounds: &bounds,
+	}), nil
+}
+
+// villageRepo scans path, or loads the tree from a snapshot file when one is
+// given. The map is sized by render.map, or to the snapshot's layout when it
+// has one.
+func villageRepo(path string, cfg config.Config, snapshotFile string) (*domain.RepoState, scene.MapBounds, error) {
+	bounds := scene.BoundsFromConfig(cfg.Render.Map)
+	if snapshotFile != "" {
+		snap, err := snapshot.Load(snapshotFile)
+		if err != nil {
+			return nil, bounds, err
+		}
+		if b, ok := snap.MapBounds(); ok {
+			bounds = b
+		}
+		repo, err := snap.Repo()
+		return repo, bounds, err
+	}
+	repo, err := scan.BuildTree(path, cfg)
+	if err != nil {
+		return nil, bounds, fmt.Errorf("scanning directory: %w", err)
+	}
+	return repo, bounds, nil
+}
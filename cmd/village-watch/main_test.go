@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/snapshot"
+)
+
+func TestCheckModes(t *testing.T) {
+	tests := []struct {
+		name                         string
+		timelapse                    bool
+		fromSnapshot, record, replay string
+		err                          string
+	}{
+		{name: "live"},
+		{name: "one mode", record: "s.jsonl"},
+		{name: "record and replay", record: "a.jsonl", replay: "b.jsonl", err: "--record and --replay cannot be combined"},
+		{name: "timelapse and snapshot", timelapse: true, fromSnapshot: "v.json", err: "--timelapse and --from-snapshot"},
+		{name: "all", timelapse: true, fromSnapshot: "v.json", record: "a.jsonl", replay: "b.jsonl", err: "--timelapse and --from-snapshot and --record and --replay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkModes(tt.timelapse, tt.fromSnapshot, tt.record, tt.replay)
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("checkModes = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTestLayoutFromSnapshotKeepsTheRecordedLayout(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"main.go", "README.md", "pkg/a.go", "pkg/b.go", "pkg/deep/c.go", "docs/guide.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.Default()
+	cfg.Git.Enabled = false
+
+	// exported from a wide, short terminal
+	repo, sc, err := exportScene(dir, cfg, 200, 30, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "village.json")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.Write(f, snapshot.Take(repo, &sc)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	_, scanned, err := testScene(dir, cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scanned.MapW == sc.MapW && scanned.MapH == sc.MapH {
+		t.Fatalf("--test sizes the map like the export (%dx%d); the test proves nothing", sc.MapW, sc.MapH)
+	}
+	_, loaded, err := testScene(dir, cfg, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.MapW != sc.MapW || loaded.MapH != sc.MapH {
+		t.Fatalf("map = %dx%d, want the recorded %dx%d", loaded.MapW, loaded.MapH, sc.MapW, sc.MapH)
+	}
+	if !reflect.DeepEqual(loaded.Slots, sc.Slots) {
+		t.Fatalf("slots = %+v, want %+v", loaded.Slots, sc.Slots)
+	}
+}
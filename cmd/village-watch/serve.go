@@ -0,0 +1,42 @@
+// cmd/village-watch/serve.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/live"
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/serve"
+)
+
+// serveVillage watches root once and shows it to every browser on addr until
+// interrupted
+func serveVillage(root string, cfg config.Config, addr string) error {
+	renderer, err := buildings.NewRendererFromConfig(root, cfg)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	themeDir := cfg.ThemesDir
+	if themeDir == "" {
+		themeDir = render.DefaultThemeDir()
+	}
+	themes, err := render.LoadThemes(themeDir)
+	if err != nil {
+		return fmt.Errorf("themes: %w", err)
+	}
+	v, err := live.Start(root, cfg, renderer)
+	if err != nil {
+		return err
+	}
+	defer v.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Printf("Serving %s on %s (Ctrl+C to stop)\n", root, addr)
+	return serve.ListenAndServe(ctx, addr, serve.New(v, cfg, renderer, themes.Get(cfg.Theme)))
+}
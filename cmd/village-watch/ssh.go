@@ -0,0 +1,37 @@
+// cmd/village-watch/ssh.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/live"
+	"example.com/village-watch/internal/sshd"
+)
+
+// sshVillage watches root once and shows it in the TUI of every allowed SSH
+// session until interrupted
+func sshVillage(root string, cfg config.Config, opts sshd.Options) error {
+	renderer, err := buildings.NewRendererFromConfig(root, cfg)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	v, err := live.Start(root, cfg, renderer)
+	if err != nil {
+		return err
+	}
+	defer v.Close()
+	srv, err := sshd.New(v, cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Printf("Serving %s over SSH on %s to the keys in %s (Ctrl+C to stop)\n", root, opts.Addr, opts.AuthorizedKeys)
+	return sshd.ListenAndServe(ctx, srv)
+}
@@ -0,0 +1,232 @@
+// internal/buildings/animation.go
+package buildings
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/harmonica"
+
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/layout"
+)
+
+// Easing curves, simulated as springs with Harmonica
+type easing int
+
+const (
+	easeBounce easing = iota // overshoots and settles, for rising scaffolding
+	easeOut                  // critically damped, for drifting smoke
+	easeIn                   // the mirror of easeOut, for falling rubble
+)
+
+// Spring parameters per curve: angular frequency over the whole animation
+// and damping ratio
+var springs = map[easing][2]float64{
+	easeBounce: {9, 0.45},
+	easeOut:    {7, 1},
+	easeIn:     {7, 1},
+}
+
+type curveKey struct {
+	kind   easing
+	frames int
+}
+
+var (
+	curvesMu sync.Mutex
+	curves   = map[curveKey][]float64{}
+)
+
+// curve returns the spring's position at each of frames+1 frames spanning
+// the animation, from 0 towards 1
+func curve(kind easing, frames int) []float64 {
+	k := curveKey{kind, frames}
+	curvesMu.Lock()
+	defer curvesMu.Unlock()
+	if c, ok := curves[k]; ok {
+		return c
+	}
+	p := springs[kind]
+	// the animation lasts one unit of time split into frames steps
+	spring := harmonica.NewSpring(harmonica.FPS(frames), p[0], p[1])
+	c := make([]float64, frames+1)
+	pos, vel := 0.0, 0.0
+	for i := 1; i <= frames; i++ {
+		pos, vel = spring.Update(pos, vel, 1)
+		c[i] = pos
+	}
+	if kind != easeBounce && c[frames] > 0 {
+		// a damped spring is only nearly settled; end exactly at 1
+		end := c[frames]
+		for i := range c {
+			c[i] /= end
+		}
+	}
+	if kind == easeIn {
+		// run the ease-out backwards: slow start, fast finish
+		in := make([]float64, frames+1)
+		for i := range in {
+			in[i] = 1 - c[frames-i]
+		}
+		c = in
+	}
+	curves[k] = c
+	return c
+}
+
+// animFrame is the frame of an animation on screen at now: the frame index
+// at fps and the eased progress
+type animFrame struct {
+	index    int
+	progress float64 // may overshoot 1 for easeBounce
+}
+
+// frameAt quantizes the time since the node's state began to whole frames
+// at fps, so the animation advances once per rendered frame
+func frameAt(node *domain.FileNode, kind easing, fps int, now time.Time) animFrame {
+	fps = max(1, fps)
+	total := node.StateExpiry.Sub(node.StateTime)
+	frames := max(1, int(math.Round(total.Seconds()*float64(fps))))
+	i := int(now.Sub(node.StateTime).Seconds() * float64(fps))
+	i = min(max(i, 0), frames)
+	return animFrame{index: i, progress: curve(kind, frames)[i]}
+}
+
+// Glyphs of the animations, unicode then ASCII
+var (
+	scaffoldGlyphs = [2][]rune{{'╬', '┼'}, {'#', '+'}}
+	smokeGlyphs    = [2][]rune{{'◦', '∘', '◌'}, {'.', 'o', 'O'}}
+	rubbleGlyphs   = [2][]rune{{'▓', '▒', '░', '▒'}, {'%', '#', ':', ';'}}
+)
+
+func glyphs(set [2][]rune, unicode bool) []rune {
+	if unicode {
+		return set[0]
+	}
+	return set[1]
+}
+
+// drawAnimation plays a node's animation state over its building:
+// scaffolding rising row by row for construction, smoke puffs drifting up
+// from the roof for writes and the building collapsing into rubble for
+// deletes
+func (r *Renderer) drawAnimation(grid [][]rune, styles [][]CellStyle, slot layout.Slot, node *domain.FileNode, design BuildingDesign, unicode bool, now time.Time) {
+	switch node.State {
+	case domain.StateNew:
+		r.drawConstruction(grid, styles, slot, node, design, unicode, frameAt(node, easeBounce, r.fps, now))
+	case domain.StateModified:
+		r.drawStructure(grid, styles, slot, node.IsDir, design, unicode)
+		r.drawSmoke(grid, styles, slot, unicode, frameAt(node, easeOut, r.fps, now))
+	case domain.StateDeleted:
+		r.drawCollapse(grid, styles, slot, node, design, unicode, frameAt(node, easeIn, r.fps, now))
+	}
+}
+
+// drawStructure draws the finished building of a node
+func (r *Renderer) drawStructure(grid [][]rune, styles [][]CellStyle, slot layout.Slot, dir bool, design BuildingDesign, unicode bool) {
+	cols, rows := 0, len(grid)
+	if rows > 0 {
+		cols = len(grid[0])
+	}
+	if dir {
+		r.drawDistrictBuilding(grid, styles, slot, design, cols, rows, unicode)
+	} else {
+		r.drawFileBuilding(grid, styles, slot, design, cols, rows, unicode)
+	}
+}
+
+// scratch draws the finished building into a grid of its own, so that
+// animations can show parts of it
+func (r *Renderer) scratch(slot layout.Slot, dir bool, design BuildingDesign, unicode bool) ([][]rune, [][]CellStyle) {
+	g := make([][]rune, slot.H)
+	for i := range g {
+		g[i] = make([]rune, slot.W)
+	}
+	st := NewStyleGrid(slot.W, slot.H)
+	r.drawStructure(g, st, layout.Slot{W: slot.W, H: slot.H}, dir, design, unicode)
+	return g, st
+}
+
+// drawConstruction shows the bottom rows of the building, topped by a row of
+// scaffolding that rises as the construction progresses
+func (r *Renderer) drawConstruction(grid [][]rune, styles [][]CellStyle, slot layout.Slot, node *domain.FileNode, design BuildingDesign, unicode bool, f animFrame) {
+	g, st := r.scratch(slot, node.IsDir, design, unicode)
+	built := min(slot.H, int(math.Round(f.progress*float64(slot.H))))
+	scaffold := glyphs(scaffoldGlyphs, unicode)
+	for dy := slot.H - built; dy < slot.H; dy++ {
+		for dx := 0; dx < slot.W; dx++ {
+			paint(grid, styles, slot.X+dx, slot.Y+dy, g[dy][dx], st[dy][dx])
+		}
+	}
+	if work := slot.H - built - 1; work >= 0 {
+		for dx := 0; dx < slot.W; dx++ {
+			ch := scaffold[(dx+f.index)%len(scaffold)]
+			paint(grid, styles, slot.X+dx, slot.Y+work, ch, CellStyle{Role: RoleWall, Archetype: design.Archetype, State: domain.StateNew})
+		}
+	}
+	// the newest row keeps the construction tint until the last frames
+	if top := slot.Y + slot.H - built; built > 0 && f.progress < 0.95 && top >= 0 && top < len(styles) {
+		for dx := 0; dx < slot.W; dx++ {
+			if x := slot.X + dx; x >= 0 && x < len(styles[top]) {
+				styles[top][x].State = domain.StateNew
+			}
+		}
+	}
+}
+
+// Smoke puffs per chimney and how many rows they rise above the roof
+const (
+	smokePuffs = 3
+	smokeRise  = 3
+)
+
+// drawSmoke tints the roof and lets puffs of smoke rise from it, each puff
+// starting a little after the previous one and growing as it drifts up. The
+// smoke only covers open ground so neighbors stay readable.
+func (r *Renderer) drawSmoke(grid [][]rune, styles [][]CellStyle, slot layout.Slot, unicode bool, f animFrame) {
+	puffs := glyphs(smokeGlyphs, unicode)
+	for dx := 0; dx < slot.W; dx++ {
+		if y, x := slot.Y, slot.X+dx; y >= 0 && y < len(styles) && x >= 0 && x < len(styles[y]) {
+			styles[y][x].State = domain.StateModified
+		}
+	}
+	chimney := slot.X + slot.W*2/3
+	for k := 0; k < smokePuffs; k++ {
+		// each puff has its own stretch of the animation
+		start := float64(k) * 0.25
+		q := (f.progress - start) / (1 - start)
+		if q <= 0 || q >= 1 {
+			continue
+		}
+		rise := int(math.Round(q * smokeRise))
+		y := slot.Y - 1 - rise
+		// puffs sway with the frame count
+		x := chimney + (k+f.index/4)%2
+		if y < 0 || y >= len(grid) || x < 0 || x >= len(grid[y]) || styles[y][x].Role != RoleGround {
+			continue
+		}
+		ch := puffs[min(len(puffs)-1, int(q*float64(len(puffs))))]
+		paint(grid, styles, x, y, ch, CellStyle{Role: RoleGround, State: domain.StateModified})
+	}
+}
+
+// drawCollapse sinks the building: its upper rows fall away faster and
+// faster, the top of what still stands crumbles into rubble and a heap of
+// rubble is left on the last frames
+func (r *Renderer) drawCollapse(grid [][]rune, styles [][]CellStyle, slot layout.Slot, node *domain.FileNode, design BuildingDesign, unicode bool, f animFrame) {
+	g, _ := r.scratch(slot, node.IsDir, design, unicode)
+	rubble := glyphs(rubbleGlyphs, unicode)
+	standing := max(1, slot.H-int(math.Round(f.progress*float64(slot.H))))
+	st := CellStyle{Role: RoleInterior, Archetype: design.Archetype, State: domain.StateDeleted}
+	for dy := slot.H - standing; dy < slot.H; dy++ {
+		for dx := 0; dx < slot.W; dx++ {
+			ch := g[dy][dx]
+			if dy == slot.H-standing {
+				ch = rubble[(dx*3+f.index)%len(rubble)]
+			}
+			paint(grid, styles, slot.X+dx, slot.Y+dy, ch, st)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package buildings
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/layout"
+)
+
+func TestCurves(t *testing.T) {
+	for _, kind := range []easing{easeBounce, easeOut, easeIn} {
+		c := curve(kind, 40)
+		if c[0] != 0 || c[40] < 0.97 || c[40] > 1.03 {
+			t.Errorf("curve %d runs %.3f..%.3f, want 0..1", kind, c[0], c[40])
+		}
+	}
+	overshoot := false
+	for _, p := range curve(easeBounce, 40) {
+		overshoot = overshoot || p > 1
+	}
+	if !overshoot {
+		t.Errorf("bounce curve never overshoots")
+	}
+	in, out := curve(easeIn, 40), curve(easeOut, 40)
+	if in[10] >= out[10] || in[10] >= 0.25 {
+		t.Errorf("ease-in at a quarter = %.3f, ease-out = %.3f; want a slow start", in[10], out[10])
+	}
+}
+
+func TestFrameAtFollowsFPS(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	n := &domain.FileNode{State: domain.StateNew, StateTime: start, StateExpiry: start.Add(2 * time.Second)}
+	tests := []struct {
+		fps     int
+		elapsed time.Duration
+		index   int
+	}{
+		{20, 0, 0},
+		{20, 49 * time.Millisecond, 0},
+		{20, 50 * time.Millisecond, 1},
+		{10, 250 * time.Millisecond, 2},
+		{20, 5 * time.Second, 40}, // held on the last frame
+	}
+	for _, tt := range tests {
+		if f := frameAt(n, easeOut, tt.fps, start.Add(tt.elapsed)); f.index != tt.index {
+			t.Errorf("fps %d after %v: frame %d, want %d", tt.fps, tt.elapsed, f.index, tt.index)
+		}
+	}
+}
+
+func TestConstructionRises(t *testing.T) {
+	r := NewRenderer()
+	design := r.registry.DesignFor(Cottage, &domain.FileNode{Name: "a.go"}, 0)
+	slot := layout.Slot{X: 1, Y: 1, W: 6, H: 4}
+	draw := func(progress float64) []string {
+		grid := make([][]rune, 6)
+		for i := range grid {
+			grid[i] = []rune(strings.Repeat(".", 8))
+		}
+		r.drawConstruction(grid, NewStyleGrid(8, 6), slot, &domain.FileNode{}, design, false, animFrame{progress: progress})
+		out := make([]string, len(grid))
+		for i, row := range grid {
+			out[i] = string(row)
+		}
+		return out
+	}
+	early := draw(0)
+	if early[1] != "........" || !strings.Contains(early[4], "#+#+") {
+		t.Fatalf("at the start only scaffolding on the ground row:\n%s", strings.Join(early, "\n"))
+	}
+	half := draw(0.5)
+	if half[1] != "........" || !strings.Contains(half[2], "#+#+") || half[4][1:7] != draw(1)[4][1:7] {
+		t.Fatalf("half way: two rows built under the scaffolding:\n%s", strings.Join(half, "\n"))
+	}
+	if done, over := strings.Join(draw(1), "\n"), strings.Join(draw(1.1), "\n"); done != over || strings.Contains(done, "#+#+") {
+		t.Fatalf("finished and overshooting frames should both show the plain building:\n%s\n\n%s", done, over)
+	}
+}
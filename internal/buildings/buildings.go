@@ -32,6 +32,8 @@ type BuildingDesign struct {
 	MinSize     int64 // Minimum file size for this design
 	MaxSize     int64 // Maximum file size (0 = no limit)
 	Probability float64 // Weight for random selection (0.0-1.0)
+	Extensions  []string // If set, only used for (and preferred by) files with these extensions
+	Source      string   // File a configured design was declared in; empty for the built-in ones
 }
 
 // UnicodeDesign contains Unicode characters for building parts
@@ -68,11 +70,39 @@ func NewRegistry() *Registry {
 
 // GetDesign returns an appropriate design for the given archetype and file size
 func (r *Registry) GetDesign(archetype Archetype, size int64, seed int64) BuildingDesign {
-	designs := r.designs[archetype]
+	return r.pick(archetype, "", size, seed)
+}
+
+// DesignFor returns a design for a node, preferring designs restricted to the
+// node's extension over the general ones of its archetype
+func (r *Registry) DesignFor(archetype Archetype, n *domain.FileNode, seed int64) BuildingDesign {
+	ext := ""
+	if !n.IsDir {
+		ext = strings.ToLower(n.Ext)
+	}
+	return r.pick(archetype, ext, n.Size, seed)
+}
+
+func (r *Registry) pick(archetype Archetype, ext string, size int64, seed int64) BuildingDesign {
+	var designs []BuildingDesign
+	if ext != "" {
+		for _, design := range r.designs[archetype] {
+			if design.matchesExt(ext) {
+				designs = append(designs, design)
+			}
+		}
+	}
+	if len(designs) == 0 {
+		for _, design := range r.designs[archetype] {
+			if len(design.Extensions) == 0 {
+				designs = append(designs, design)
+			}
+		}
+	}
 	if len(designs) == 0 {
 		// Fallback to cottage if no designs found
 		if archetype != Cottage {
-			return r.GetDesign(Cottage, size, seed)
+			return r.pick(Cottage, ext, size, seed)
 		}
 		// Ultimate fallback
 		return BuildingDesign{
@@ -121,11 +151,36 @@ func (r *Registry) GetDesign(archetype Archetype, size int64, seed int64) Buildi
 	return validDesigns[len(validDesigns)-1] // Fallback to last
 }
 
+func (d BuildingDesign) matchesExt(ext string) bool {
+	for _, e := range d.Extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
 // AddDesign adds a new building design to the registry
 func (r *Registry) AddDesign(design BuildingDesign) {
 	r.designs[design.Archetype] = append(r.designs[design.Archetype], design)
 }
 
+// AddDesigns adds configured designs once their size ranges check out
+// against each other and against the designs already registered
+func (r *Registry) AddDesigns(designs []BuildingDesign) error {
+	var all []BuildingDesign
+	for a := Cottage; a <= District; a++ {
+		all = append(all, r.designs[a]...)
+	}
+	if err := checkOverlaps(append(all, designs...)); err != nil {
+		return err
+	}
+	for _, d := range designs {
+		r.AddDesign(d)
+	}
+	return nil
+}
+
 // GetArchetype determines the archetype from a file node
 func GetArchetype(n *domain.FileNode) Archetype {
 	if n.IsDir {
@@ -0,0 +1,109 @@
+// internal/buildings/cells.go
+package buildings
+
+import (
+	"strings"
+
+	"example.com/village-watch/internal/domain"
+)
+
+// Role says which part of the map a cell belongs to
+type Role uint8
+
+const (
+	RoleGround   Role = iota // Grass/empty ground
+	RoleRoad                 // Road tiles
+	RoleWall                 // Building walls and corners
+	RoleDoor                 // Building entrances
+	RoleRoof                 // Roof/feature glyphs
+	RoleInterior             // Building floor
+	RoleLabel                // District name labels
+)
+
+// Lamp is how a lantern is lit by its log, from dark to an error glow
+type Lamp uint8
+
+const (
+	LampDark   Lamp = iota // No recent lines
+	LampLit                // Some lines
+	LampBright             // A busy log
+	LampWarn               // Warnings within the tailer's window
+	LampError              // Errors within the tailer's window
+)
+
+var lampNames = []string{
+	LampDark:   "dark",
+	LampLit:    "lit",
+	LampBright: "bright",
+	LampWarn:   "warn",
+	LampError:  "error",
+}
+
+func (l Lamp) String() string {
+	if int(l) < len(lampNames) {
+		return lampNames[l]
+	}
+	return "lamp"
+}
+
+// ParseLamp resolves a lamp name as used in themes
+func ParseLamp(name string) (Lamp, bool) {
+	for l, n := range lampNames {
+		if strings.EqualFold(n, name) {
+			return Lamp(l), true
+		}
+	}
+	return LampDark, false
+}
+
+// Lines per second from which a lantern burns bright
+const brightLinesPerSec = 5
+
+// LampFor classifies a log's activity
+func LampFor(a domain.LogActivity) Lamp {
+	switch {
+	case a.Errors > 0:
+		return LampError
+	case a.Warnings > 0:
+		return LampWarn
+	case a.LinesPerSec >= brightLinesPerSec:
+		return LampBright
+	case a.LinesPerSec >= 0.05:
+		return LampLit
+	default:
+		return LampDark
+	}
+}
+
+// CellStyle tags a map cell so renderers can color it from the theme
+type CellStyle struct {
+	Role      Role
+	Archetype Archetype
+	State     domain.FileState
+	Highlight bool // part of a search match
+	Cursor    bool // outline of the selected building
+	Dim       bool // filtered out by the activity filter
+	Git       domain.GitState
+	Lamp      Lamp // light of a lantern or its glow on the ground
+}
+
+// NewStyleGrid allocates a style grid matching a cols x rows rune grid
+func NewStyleGrid(cols, rows int) [][]CellStyle {
+	styles := make([][]CellStyle, rows)
+	for i := range styles {
+		styles[i] = make([]CellStyle, cols)
+	}
+	return styles
+}
+
+// paint writes a glyph and its style, ignoring cells outside the grid.
+// A nil styles grid only receives glyphs.
+func paint(grid [][]rune, styles [][]CellStyle, x, y int, r rune, st CellStyle) {
+	if y < 0 || y >= len(grid) || x < 0 || x >= len(grid[y]) {
+		return
+	}
+	grid[y][x] = r
+	if styles != nil && y < len(styles) && x < len(styles[y]) {
+		styles[y][x] = st
+	}
+}
@@ -0,0 +1,234 @@
+// internal/buildings/designs.go
+package buildings
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"gopkg.in/yaml.v3"
+
+	"example.com/village-watch/internal/config"
+)
+
+// designPack is the file format of designs/*.yaml
+type designPack struct {
+	Designs []config.DesignCfg `yaml:"designs"`
+}
+
+// defaultASCII is used when a design only declares Unicode glyphs
+var defaultASCII = ASCIIDesign{Corner: '#', Wall: '#', Door: '=', Interior: '.', Roof: '^'}
+
+// LoadDesignPacks reads every *.yaml/*.yml pack in dir, in name order.
+// A missing directory is not an error.
+func LoadDesignPacks(dir string) ([]BuildingDesign, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading design packs: %w", err)
+	}
+	var out []BuildingDesign
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading design pack: %w", err)
+		}
+		var pack designPack
+		if err := yaml.Unmarshal(b, &pack); err != nil {
+			return nil, fmt.Errorf("design pack %s: %w", e.Name(), err)
+		}
+		designs, err := ParseDesigns(pack.Designs)
+		if err != nil {
+			return nil, fmt.Errorf("design pack %s: %w", e.Name(), err)
+		}
+		for i := range designs {
+			designs[i].Source = e.Name()
+		}
+		out = append(out, designs...)
+	}
+	return out, nil
+}
+
+// ParseDesigns validates design declarations and converts them to
+// BuildingDesigns. Designs of the same archetype whose size ranges overlap
+// must use identical ranges, so that selection among them is purely by weight.
+func ParseDesigns(cfgs []config.DesignCfg) ([]BuildingDesign, error) {
+	out := make([]BuildingDesign, 0, len(cfgs))
+	for i, c := range cfgs {
+		d, err := parseDesign(c)
+		if err != nil {
+			name := c.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return nil, fmt.Errorf("design %s: %w", name, err)
+		}
+		out = append(out, d)
+	}
+	if err := checkOverlaps(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parseDesign(c config.DesignCfg) (BuildingDesign, error) {
+	if strings.TrimSpace(c.Name) == "" {
+		return BuildingDesign{}, errors.New("missing name")
+	}
+	archetype, err := parseDesignArchetype(c.Archetype)
+	if err != nil {
+		return BuildingDesign{}, err
+	}
+	if c.MinSize < 0 || c.MaxSize < 0 {
+		return BuildingDesign{}, errors.New("sizes must not be negative")
+	}
+	if c.MaxSize != 0 && c.MaxSize < c.MinSize {
+		return BuildingDesign{}, fmt.Errorf("max_size %d below min_size %d", c.MaxSize, c.MinSize)
+	}
+	weight := 1.0
+	if c.Weight != nil {
+		weight = *c.Weight
+	}
+	if weight < 0 {
+		return BuildingDesign{}, errors.New("weight must not be negative")
+	}
+
+	d := BuildingDesign{
+		Name:        c.Name,
+		Archetype:   archetype,
+		MinSize:     c.MinSize,
+		MaxSize:     c.MaxSize,
+		Probability: weight,
+	}
+	for _, ext := range c.Extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		d.Extensions = append(d.Extensions, ext)
+	}
+
+	u, err := parseGlyphs(c.Unicode, false)
+	if err != nil {
+		return BuildingDesign{}, fmt.Errorf("unicode: %w", err)
+	}
+	d.Unicode = UnicodeDesign(u)
+	if c.ASCII == (config.GlyphsCfg{}) {
+		d.ASCII = defaultASCII
+	} else {
+		a, err := parseGlyphs(c.ASCII, true)
+		if err != nil {
+			return BuildingDesign{}, fmt.Errorf("ascii: %w", err)
+		}
+		d.ASCII = a
+	}
+	return d, nil
+}
+
+// parseDesignArchetype accepts every archetype, including district
+func parseDesignArchetype(name string) (Archetype, error) {
+	if strings.EqualFold(strings.TrimSpace(name), District.String()) {
+		return District, nil
+	}
+	return ParseArchetype(name)
+}
+
+// parseGlyphs checks that every glyph is a single character occupying one
+// terminal cell; the roof falls back to the interior glyph when omitted.
+func parseGlyphs(g config.GlyphsCfg, asciiOnly bool) (ASCIIDesign, error) {
+	var out ASCIIDesign
+	fields := []struct {
+		name string
+		val  string
+		dst  *rune
+	}{
+		{"corner", g.Corner, &out.Corner},
+		{"wall", g.Wall, &out.Wall},
+		{"door", g.Door, &out.Door},
+		{"interior", g.Interior, &out.Interior},
+		{"roof", g.Roof, &out.Roof},
+	}
+	for _, f := range fields {
+		if f.val == "" {
+			if f.name == "roof" {
+				continue
+			}
+			return out, fmt.Errorf("missing %s glyph", f.name)
+		}
+		r, size := utf8.DecodeRuneInString(f.val)
+		if size != len(f.val) || r == utf8.RuneError {
+			return out, fmt.Errorf("%s glyph %q must be a single character", f.name, f.val)
+		}
+		if asciiOnly && (r < 0x20 || r > 0x7e) {
+			return out, fmt.Errorf("%s glyph %q is not printable ASCII", f.name, f.val)
+		}
+		if w := runewidth.RuneWidth(r); w != 1 {
+			return out, fmt.Errorf("%s glyph %q is %d cells wide, want 1", f.name, f.val, w)
+		}
+		*f.dst = r
+	}
+	if out.Roof == 0 {
+		out.Roof = out.Interior
+	}
+	return out, nil
+}
+
+// checkOverlaps rejects partially overlapping size ranges within an
+// archetype and extension set.
+func checkOverlaps(designs []BuildingDesign) error {
+	groups := map[string][]BuildingDesign{}
+	for _, d := range designs {
+		exts := append([]string{}, d.Extensions...)
+		sort.Strings(exts)
+		key := d.Archetype.String() + "|" + strings.Join(exts, ",")
+		groups[key] = append(groups[key], d)
+	}
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		group := groups[key]
+		for i := 0; i < len(group); i++ {
+			for j := i + 1; j < len(group); j++ {
+				a, b := group[i], group[j]
+				same := a.MinSize == b.MinSize && a.MaxSize == b.MaxSize
+				if !same && sizeRangesOverlap(a, b) {
+					return fmt.Errorf("designs %s and %s have overlapping size ranges; to share one, give both exactly the same min_size and max_size", a.label(), b.label())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// label names a design and, once merged with others, where it came from
+func (d BuildingDesign) label() string {
+	if d.Source == "" {
+		return d.Name
+	}
+	return fmt.Sprintf("%s (%s)", d.Name, d.Source)
+}
+
+func sizeRangesOverlap(a, b BuildingDesign) bool {
+	upper := func(d BuildingDesign) int64 {
+		if d.MaxSize == 0 {
+			return 1<<63 - 1
+		}
+		return d.MaxSize
+	}
+	return a.MinSize <= upper(b) && b.MinSize <= upper(a)
+}
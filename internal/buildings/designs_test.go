@@ -0,0 +1,146 @@
+package buildings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+)
+
+const protoPack = `designs:
+  - name: Proto Forge
+    archetype: warehouse
+    extensions: [".proto"]
+    weight: 2
+    unicode: { corner: "#", wall: "#", door: "=", interior: "▚", roof: "⚒" }
+    ascii:   { corner: "#", wall: "#", door: "=", interior: "%", roof: "P" }
+`
+
+func TestLoadDesignPacks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "proto.yaml"), []byte(protoPack), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	designs, err := LoadDesignPacks(dir)
+	if err != nil {
+		t.Fatalf("LoadDesignPacks: %v", err)
+	}
+	if len(designs) != 1 || designs[0].Name != "Proto Forge" || designs[0].Archetype != Warehouse {
+		t.Fatalf("unexpected designs: %+v", designs)
+	}
+
+	reg := NewRegistry()
+	reg.AddDesign(designs[0])
+	proto := &domain.FileNode{Name: "api.proto", Ext: ".proto", Size: 10}
+	if got := reg.DesignFor(Warehouse, proto, 1); got.Name != "Proto Forge" {
+		t.Fatalf("expected Proto Forge for .proto files, got %s", got.Name)
+	}
+	zip := &domain.FileNode{Name: "a.zip", Ext: ".zip", Size: 10}
+	if got := reg.DesignFor(Warehouse, zip, 1); got.Name == "Proto Forge" {
+		t.Fatalf("extension-restricted design used for .zip")
+	}
+
+	if designs, err := LoadDesignPacks(filepath.Join(dir, "missing")); err != nil || designs != nil {
+		t.Fatalf("missing dir should be ignored, got %v, %v", designs, err)
+	}
+}
+
+func TestParseDesignsValidation(t *testing.T) {
+	glyphs := config.GlyphsCfg{Corner: "#", Wall: "#", Door: "=", Interior: "."}
+	tests := []struct {
+		name    string
+		designs []config.DesignCfg
+		wantErr string
+	}{
+		{"valid", []config.DesignCfg{{Name: "A", Archetype: "cottage", Unicode: glyphs}}, ""},
+		{"unknown archetype", []config.DesignCfg{{Name: "A", Archetype: "castle", Unicode: glyphs}}, "unknown archetype"},
+		{"wide glyph", []config.DesignCfg{{Name: "A", Archetype: "library", Unicode: config.GlyphsCfg{Corner: "#", Wall: "#", Door: "=", Interior: "📚"}}}, "cells wide"},
+		{"multi char", []config.DesignCfg{{Name: "A", Archetype: "library", Unicode: config.GlyphsCfg{Corner: "##", Wall: "#", Door: "=", Interior: "."}}}, "single character"},
+		{"non-ascii fallback", []config.DesignCfg{{Name: "A", Archetype: "kiosk", Unicode: glyphs, ASCII: config.GlyphsCfg{Corner: "▣", Wall: "#", Door: "=", Interior: "."}}}, "printable ASCII"},
+		{"inverted range", []config.DesignCfg{{Name: "A", Archetype: "kiosk", MinSize: 10, MaxSize: 5, Unicode: glyphs}}, "below min_size"},
+		{"overlap", []config.DesignCfg{
+			{Name: "A", Archetype: "kiosk", MinSize: 0, MaxSize: 100, Unicode: glyphs},
+			{Name: "B", Archetype: "kiosk", MinSize: 50, MaxSize: 0, Unicode: glyphs},
+		}, "overlapping"},
+		{"identical ranges share by weight", []config.DesignCfg{
+			{Name: "A", Archetype: "kiosk", MaxSize: 100, Unicode: glyphs},
+			{Name: "B", Archetype: "kiosk", MaxSize: 100, Unicode: glyphs},
+			{Name: "C", Archetype: "kiosk", MinSize: 101, Unicode: glyphs},
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDesigns(tt.designs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDesignOverlapsAcrossSources(t *testing.T) {
+	const cottages = `designs:
+  - name: Shed
+    archetype: cottage
+    max_size: 4096
+    unicode: { corner: "#", wall: "#", door: "=", interior: "." }
+`
+	const protoShed = `designs:
+  - name: Proto Shed
+    archetype: warehouse
+    extensions: [".proto"]
+    min_size: 100
+    unicode: { corner: "#", wall: "#", door: "=", interior: "." }
+`
+	glyphs := config.GlyphsCfg{Corner: "#", Wall: "#", Door: "=", Interior: "."}
+	tests := []struct {
+		name    string
+		packs   map[string]string
+		inline  []config.DesignCfg
+		wantErr string
+	}{
+		{"pack against built-in", map[string]string{"cottages.yaml": cottages}, nil, "Shed (cottages.yaml)"},
+		{"pack against pack", map[string]string{"proto.yaml": protoPack, "shed.yaml": protoShed}, nil, "Proto Forge (proto.yaml) and Proto Shed (shed.yaml)"},
+		{"pack against village.yml", map[string]string{"proto.yaml": protoPack}, []config.DesignCfg{
+			{Name: "Big Proto", Archetype: "warehouse", Extensions: []string{"proto"}, MinSize: 1 << 20, Unicode: glyphs},
+		}, "Big Proto (village.yml)"},
+		{"same ranges as the built-ins", nil, []config.DesignCfg{
+			{Name: "Hut", Archetype: "cottage", MaxSize: 10240, Unicode: glyphs},
+			{Name: "Vault", Archetype: "warehouse", MinSize: 1048577, Unicode: glyphs},
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.Mkdir(filepath.Join(root, "designs"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, pack := range tt.packs {
+				if err := os.WriteFile(filepath.Join(root, "designs", name), []byte(pack), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := config.Default()
+			cfg.DesignsDir, cfg.Designs = "designs", tt.inline
+			_, err := NewRendererFromConfig(root, cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "overlapping") {
+				t.Fatalf("error = %v, want overlapping ranges naming %q", err, tt.wantErr)
+			}
+		})
+	}
+}
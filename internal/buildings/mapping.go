@@ -0,0 +1,117 @@
+// internal/buildings/mapping.go
+package buildings
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+)
+
+// archetypeNames are the names accepted in config files, indexed by Archetype
+var archetypeNames = []string{
+	Cottage:   "cottage",
+	Library:   "library",
+	Kiosk:     "kiosk",
+	Atelier:   "atelier",
+	Warehouse: "warehouse",
+	Academy:   "academy",
+	Lantern:   "lantern",
+	Shrine:    "shrine",
+	District:  "district",
+}
+
+// String returns the config name of the archetype
+func (a Archetype) String() string {
+	if a >= 0 && int(a) < len(archetypeNames) {
+		return archetypeNames[a]
+	}
+	return fmt.Sprintf("archetype(%d)", int(a))
+}
+
+// ParseArchetype resolves a file archetype name as used in village.yml.
+// District is reserved for directories and cannot be mapped to.
+func ParseArchetype(name string) (Archetype, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for a, n := range archetypeNames {
+		if n == name && Archetype(a) != District {
+			return Archetype(a), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown archetype %q", name)
+}
+
+// Mapping applies the user's `mapping:` section before the built-in rules.
+// Keys are matched most specific first: exact filenames (or relative paths),
+// then glob patterns, then extensions. Globs containing a slash match the
+// path relative to the repo root; others match the base name.
+type Mapping struct {
+	exact map[string]Archetype
+	globs []globRule
+	exts  map[string]Archetype
+}
+
+type globRule struct {
+	pattern   string
+	archetype Archetype
+}
+
+// NewMapping validates cfg and compiles it into a Mapping
+func NewMapping(cfg config.MappingCfg) (*Mapping, error) {
+	m := &Mapping{exact: map[string]Archetype{}, exts: map[string]Archetype{}}
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // deterministic glob order
+	for _, key := range keys {
+		a, err := ParseArchetype(cfg[key])
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q: %w", key, err)
+		}
+		k := filepath.ToSlash(strings.TrimSpace(key))
+		switch {
+		case strings.ContainsAny(k, "*?["):
+			if _, err := path.Match(k, ""); err != nil {
+				return nil, fmt.Errorf("mapping %q: %w", key, err)
+			}
+			m.globs = append(m.globs, globRule{pattern: k, archetype: a})
+		case strings.HasPrefix(k, ".") && !strings.Contains(k[1:], ".") && !strings.Contains(k, "/"):
+			m.exts[strings.ToLower(k)] = a
+		default:
+			m.exact[strings.TrimPrefix(k, "./")] = a
+		}
+	}
+	return m, nil
+}
+
+// Archetype returns the mapped archetype for a file at rel (slash-separated,
+// relative to the repo root) and whether any rule matched.
+func (m *Mapping) Archetype(n *domain.FileNode, rel string) (Archetype, bool) {
+	if m == nil || n == nil || n.IsDir {
+		return 0, false
+	}
+	if a, ok := m.exact[rel]; ok {
+		return a, true
+	}
+	if a, ok := m.exact[n.Name]; ok {
+		return a, true
+	}
+	for _, g := range m.globs {
+		subject := n.Name
+		if strings.Contains(g.pattern, "/") {
+			subject = rel
+		}
+		if ok, _ := path.Match(g.pattern, subject); ok {
+			return g.archetype, true
+		}
+	}
+	if a, ok := m.exts[strings.ToLower(n.Ext)]; ok {
+		return a, true
+	}
+	return 0, false
+}
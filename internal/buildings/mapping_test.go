@@ -0,0 +1,56 @@
+package buildings
+
+import (
+	"path"
+	"testing"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+)
+
+func TestMappingPrecedence(t *testing.T) {
+	m, err := NewMapping(config.MappingCfg{
+		".md":         "library",
+		".proto":      "Warehouse",
+		"docs/*.txt":  "academy",
+		"*_gen.go":    "atelier",
+		"Makefile":    "kiosk",
+		"cmd/main.go": "shrine",
+		".env":        "lantern",
+	})
+	if err != nil {
+		t.Fatalf("NewMapping: %v", err)
+	}
+	tests := []struct {
+		rel    string
+		want   Archetype
+		mapped bool
+	}{
+		{"README.md", Library, true},
+		{"api/v1.PROTO", Warehouse, true},
+		{"docs/notes.txt", Academy, true},
+		{"notes.txt", 0, false},
+		{"pkg/x_gen.go", Atelier, true},
+		{"Makefile", Kiosk, true},
+		{"sub/Makefile", Kiosk, true},
+		{"cmd/main.go", Shrine, true},
+		{"other/main.go", 0, false},
+		{".env", Lantern, true},
+	}
+	for _, tt := range tests {
+		n := &domain.FileNode{Path: "/r/" + tt.rel, Name: path.Base(tt.rel), Ext: domain.Ext(tt.rel)}
+		got, ok := m.Archetype(n, tt.rel)
+		if ok != tt.mapped || (ok && got != tt.want) {
+			t.Errorf("Archetype(%q) = %v,%v want %v,%v", tt.rel, got, ok, tt.want, tt.mapped)
+		}
+	}
+}
+
+func TestMappingUnknownArchetype(t *testing.T) {
+	if _, err := NewMapping(config.MappingCfg{".md": "libary"}); err == nil {
+		t.Fatalf("expected error for unknown archetype")
+	}
+	if _, err := NewMapping(config.MappingCfg{"src": "district"}); err == nil {
+		t.Fatalf("expected error when mapping files to district")
+	}
+}
@@ -2,18 +2,27 @@
 package buildings
 
 import (
+	"fmt"
 	"hash/fnv"
+	"path/filepath"
+	"time"
 
+	"example.com/village-watch/internal/config"
 	"example.com/village-watch/internal/domain"
 	"example.com/village-watch/internal/layout"
 )
 
+// defaultFPS steps animations when the configuration does not say
+const defaultFPS = 20
+
 // Renderer handles drawing buildings using the design registry
 type Renderer struct {
 	registry *Registry
+	mapping  *Mapping
+	fps      int // frame rate animations are stepped at
 }
 
-func (r *Renderer) RenderLabel(grid [][]rune, slot layout.Slot, name string, cols, rows int) {
+func (r *Renderer) RenderLabel(grid [][]rune, styles [][]CellStyle, slot layout.Slot, name string, cols, rows int) {
 	x, y := slot.X, slot.Y
 	w := slot.W
 	if w <= 0 || len(name) == 0 { return }
@@ -33,7 +42,7 @@ func (r *Renderer) RenderLabel(grid [][]rune, slot layout.Slot, name string, col
 	for i := 0; i < len(runes); i++ {
 		px := startX + i
 		if px >= 0 && px < cols && labelY >= 0 && labelY < rows {
-			grid[labelY][px] = runes[i]
+			paint(grid, styles, px, labelY, runes[i], CellStyle{Role: RoleLabel, Archetype: District})
 		}
 	}
 }
@@ -42,7 +51,66 @@ func (r *Renderer) RenderLabel(grid [][]rune, slot layout.Slot, name string, col
 func NewRenderer() *Renderer {
 	return &Renderer{
 		registry: NewRegistry(),
+		fps:      defaultFPS,
+	}
+}
+
+// NewRendererFromConfig creates a renderer that honors the user's archetype
+// mapping and adds design packs from the designs directory under root and the
+// inline `designs:` section, reporting invalid entries as errors
+func NewRendererFromConfig(root string, cfg config.Config) (*Renderer, error) {
+	mapping, err := NewMapping(cfg.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRenderer()
+	r.mapping = mapping
+	if cfg.FPS > 0 {
+		r.fps = cfg.FPS
+	}
+
+	var designs []BuildingDesign
+	if cfg.DesignsDir != "" {
+		dir := cfg.DesignsDir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		packs, err := LoadDesignPacks(dir)
+		if err != nil {
+			return nil, err
+		}
+		designs = append(designs, packs...)
+	}
+	inline, err := ParseDesigns(cfg.Designs)
+	if err != nil {
+		return nil, fmt.Errorf("designs: %w", err)
+	}
+	for i := range inline {
+		inline[i].Source = "village.yml"
+	}
+	designs = append(designs, inline...)
+	if err := r.registry.AddDesigns(designs); err != nil {
+		return nil, fmt.Errorf("designs: %w", err)
+	}
+	return r, nil
+}
+
+// Archetype resolves a node's archetype, consulting the configured mapping
+// before the built-in rules
+func (r *Renderer) Archetype(repo *domain.RepoState, n *domain.FileNode) Archetype {
+	if r.mapping != nil && repo != nil {
+		if rel, err := filepath.Rel(repo.RootPath, n.Path); err == nil {
+			if a, ok := r.mapping.Archetype(n, filepath.ToSlash(rel)); ok {
+				return a
+			}
+		}
 	}
+	return GetArchetype(n)
+}
+
+// Design returns the design a node is drawn with
+func (r *Renderer) Design(repo *domain.RepoState, n *domain.FileNode) BuildingDesign {
+	return r.registry.DesignFor(r.Archetype(repo, n), n, r.generateSeed(n.Path, n.Size))
 }
 
 // GetRegistry returns the building design registry for customization
@@ -51,33 +119,115 @@ func (r *Renderer) GetRegistry() *Registry {
 }
 
 // RenderBuilding draws a building at the given slot using the appropriate design
-func (r *Renderer) RenderBuilding(grid [][]rune, repo *domain.RepoState, slot layout.Slot, cols, rows int, unicode bool) {
+func (r *Renderer) RenderBuilding(grid [][]rune, styles [][]CellStyle, repo *domain.RepoState, slot layout.Slot, cols, rows int, unicode bool) {
 	node := repo.Index[slot.Path]
 	if node == nil {
 		return
 	}
+	if slot.Kind == layout.SlotHamlet {
+		r.drawHamlet(grid, styles, slot, cols, rows, unicode)
+		return
+	}
 	
+	archetype := r.Archetype(repo, node)
+	design := r.registry.DesignFor(archetype, node, r.generateSeed(node.Path, node.Size))
+
 	// Handle animation states first
 	if node.IsStateActive() {
-		r.drawAnimationEffect(grid, slot, node.State, cols, rows, unicode)
+		r.drawAnimation(grid, styles, slot, node, design, unicode, time.Now())
 		return
 	}
 	
-	// Get appropriate design
-	archetype := GetArchetype(node)
-	seed := r.generateSeed(node.Path, node.Size)
-	design := r.registry.GetDesign(archetype, node.Size, seed)
-	
 	// Render the building using the design
 	if node.IsDir {
-		r.drawDistrictBuilding(grid, slot, design, cols, rows, unicode)
+		r.drawDistrictBuilding(grid, styles, slot, design, cols, rows, unicode)
 	} else {
-		r.drawFileBuilding(grid, slot, design, cols, rows, unicode)
+		r.drawFileBuilding(grid, styles, slot, design, cols, rows, unicode)
+	}
+	if node.Git != domain.GitClean {
+		r.drawGitBanner(grid, styles, slot, node.Git, !node.IsDir, unicode)
+	}
+	if archetype == Lantern && !node.IsDir {
+		r.drawLanternLight(grid, styles, slot, node.Log, unicode, time.Now())
+	}
+}
+
+// lanternFlames are the flicker frames of a lit lantern, unicode then ASCII
+var lanternFlames = [2][]rune{{'✶', '✦', '✧', '✦'}, {'*', '+', '\'', '+'}}
+
+// flickerPeriod is how long each flame frame shows; busier and more
+// alarming logs flicker faster
+var flickerPeriod = map[Lamp]int64{LampLit: 400, LampBright: 150, LampWarn: 200, LampError: 90}
+
+// drawLanternLight lights a lantern by its log: the building takes the lamp
+// style, its interior flickers, and warnings or errors cast a glow on the
+// ground around it
+func (r *Renderer) drawLanternLight(grid [][]rune, styles [][]CellStyle, slot layout.Slot, log domain.LogActivity, unicode bool, now time.Time) {
+	lamp := LampFor(log)
+	if lamp == LampDark || styles == nil {
+		return
+	}
+	flames := lanternFlames[1]
+	if unicode {
+		flames = lanternFlames[0]
+	}
+	frame := int(now.UnixMilli() / flickerPeriod[lamp])
+	for dy := -1; dy <= slot.H; dy++ {
+		for dx := -1; dx <= slot.W; dx++ {
+			x, y := slot.X+dx, slot.Y+dy
+			if y < 0 || y >= len(styles) || x < 0 || x >= len(styles[y]) {
+				continue
+			}
+			st := &styles[y][x]
+			inside := dx >= 0 && dx < slot.W && dy >= 0 && dy < slot.H
+			switch {
+			case !inside:
+				if lamp >= LampWarn && st.Role == RoleGround {
+					st.Lamp = lamp
+				}
+			case st.Git != domain.GitClean:
+				// keep the git banner readable
+			case dx > 0 && dx < slot.W-1 && dy > 0 && dy < slot.H-1:
+				grid[y][x] = flames[(frame+dx*3+dy)%len(flames)]
+				st.Lamp = lamp
+			default:
+				st.Lamp = lamp
+			}
+		}
 	}
 }
 
+// gitFlags are the banner glyphs by git state, unicode then ASCII
+var gitFlags = map[domain.GitState][2]rune{
+	domain.GitUntracked:  {'?', '?'},
+	domain.GitStaged:     {'✚', '+'},
+	domain.GitModified:   {'✎', '*'},
+	domain.GitConflicted: {'‼', '!'},
+}
+
+// drawGitBanner flies a flag for the git state at the top-right corner and,
+// for files, colors the roof row with it
+func (r *Renderer) drawGitBanner(grid [][]rune, styles [][]CellStyle, slot layout.Slot, state domain.GitState, roof, unicode bool) {
+	if styles == nil || slot.W <= 0 || slot.H <= 0 {
+		return
+	}
+	y := slot.Y
+	if roof {
+		for dx := 0; dx < slot.W-1; dx++ {
+			if y >= 0 && y < len(styles) && slot.X+dx >= 0 && slot.X+dx < len(styles[y]) {
+				styles[y][slot.X+dx].Git = state
+			}
+		}
+	}
+	flag := gitFlags[state][1]
+	if unicode {
+		flag = gitFlags[state][0]
+	}
+	paint(grid, styles, slot.X+slot.W-1, y, flag, CellStyle{Role: RoleRoof, Archetype: District, Git: state})
+}
+
 // drawFileBuilding renders a file as a building using the specified design
-func (r *Renderer) drawFileBuilding(grid [][]rune, slot layout.Slot, design BuildingDesign, cols, rows int, unicode bool) {
+func (r *Renderer) drawFileBuilding(grid [][]rune, styles [][]CellStyle, slot layout.Slot, design BuildingDesign, cols, rows int, unicode bool) {
 	x, y := slot.X, slot.Y
 	w, h := slot.W, slot.H
 	
@@ -102,17 +252,17 @@ func (r *Renderer) drawFileBuilding(grid [][]rune, slot layout.Slot, design Buil
 				if dx == 0 || dx == w-1 || dy == 0 || dy == h-1 {
 					// Building outline
 					if (dx == 0 || dx == w-1) && (dy == 0 || dy == h-1) {
-						grid[y+dy][x+dx] = corner // Corners
+						paint(grid, styles, x+dx, y+dy, corner, CellStyle{Role: RoleWall, Archetype: design.Archetype}) // Corners
 					} else {
-						grid[y+dy][x+dx] = wall // Walls
+						paint(grid, styles, x+dx, y+dy, wall, CellStyle{Role: RoleWall, Archetype: design.Archetype}) // Walls
 					}
 					// Door in the middle of bottom wall
 					if dy == h-1 && dx == w/2 {
-						grid[y+dy][x+dx] = door
+						paint(grid, styles, x+dx, y+dy, door, CellStyle{Role: RoleDoor, Archetype: design.Archetype})
 					}
 				} else {
 					// Interior
-					grid[y+dy][x+dx] = interior
+					paint(grid, styles, x+dx, y+dy, interior, CellStyle{Role: RoleInterior, Archetype: design.Archetype})
 				}
 			}
 		}
@@ -120,7 +270,7 @@ func (r *Renderer) drawFileBuilding(grid [][]rune, slot layout.Slot, design Buil
 }
 
 // drawDistrictBuilding renders a directory as a district building
-func (r *Renderer) drawDistrictBuilding(grid [][]rune, slot layout.Slot, design BuildingDesign, cols, rows int, unicode bool) {
+func (r *Renderer) drawDistrictBuilding(grid [][]rune, styles [][]CellStyle, slot layout.Slot, design BuildingDesign, cols, rows int, unicode bool) {
 	x, y := slot.X, slot.Y
 	w, h := slot.W, slot.H
 	
@@ -147,22 +297,22 @@ func (r *Renderer) drawDistrictBuilding(grid [][]rune, slot layout.Slot, design
 				if dx == 0 || dx == w-1 || dy == 0 || dy == h-1 {
 					// Building outline
 					if (dx == 0 || dx == w-1) && (dy == 0 || dy == h-1) {
-						grid[y+dy][x+dx] = corner // Corners
+						paint(grid, styles, x+dx, y+dy, corner, CellStyle{Role: RoleWall, Archetype: design.Archetype}) // Corners
 					} else {
-						grid[y+dy][x+dx] = wall // Walls
+						paint(grid, styles, x+dx, y+dy, wall, CellStyle{Role: RoleWall, Archetype: design.Archetype}) // Walls
 					}
 					// Door in the middle of bottom wall
 					if dy == h-1 && dx == w/2 {
-						grid[y+dy][x+dx] = door
+						paint(grid, styles, x+dx, y+dy, door, CellStyle{Role: RoleDoor, Archetype: design.Archetype})
 					}
 				} else {
 					// Interior - show different room features for districts
 					if dx == 1 && dy == 1 {
-						grid[y+dy][x+dx] = roof // Main feature in top-left
+						paint(grid, styles, x+dx, y+dy, roof, CellStyle{Role: RoleRoof, Archetype: design.Archetype}) // Main feature in top-left
 					} else if dx == w-2 && dy == 1 && w > 3 {
-						grid[y+dy][x+dx] = corner // Secondary feature in top-right
+						paint(grid, styles, x+dx, y+dy, corner, CellStyle{Role: RoleRoof, Archetype: design.Archetype}) // Secondary feature in top-right
 					} else {
-						grid[y+dy][x+dx] = interior // Floor
+						paint(grid, styles, x+dx, y+dy, interior, CellStyle{Role: RoleInterior, Archetype: design.Archetype}) // Floor
 					}
 				}
 			}
@@ -170,40 +320,42 @@ func (r *Renderer) drawDistrictBuilding(grid [][]rune, slot layout.Slot, design
 	}
 }
 
-// drawAnimationEffect renders animation states across the entire building
-func (r *Renderer) drawAnimationEffect(grid [][]rune, slot layout.Slot, state domain.FileState, cols, rows int, unicode bool) {
-	x, y := slot.X, slot.Y
-	w, h := slot.W, slot.H
-	
-	// Get animation glyph
-	var animGlyph rune
-	switch state {
-	case domain.StateNew:
-		animGlyph = '+' // Construction
-	case domain.StateModified:
-		animGlyph = '~' // Activity/smoke
-	case domain.StateDeleted:
-		animGlyph = 'X' // Demolished
-	default:
-		animGlyph = '?'
+// drawHamlet renders an aggregate of buildings that did not fit as a cluster
+// of huts with a "+N" count, left out when only empty directories are folded
+// into it
+func (r *Renderer) drawHamlet(grid [][]rune, styles [][]CellStyle, slot layout.Slot, cols, rows int, unicode bool) {
+	hut := '^'
+	if unicode {
+		hut = '⌂'
 	}
-	
-	// Fill the entire building area based on animation type
-	for dx := 0; dx < w && x+dx < cols; dx++ {
-		for dy := 0; dy < h && y+dy < rows; dy++ {
-			if x+dx >= 0 && y+dy >= 0 {
-				// For construction/demolition, show effect throughout
-				if state == domain.StateNew || state == domain.StateDeleted {
-					grid[y+dy][x+dx] = animGlyph
-				} else if state == domain.StateModified {
-					// For modification, show smoke/activity effects around building perimeter
-					if dx == 0 || dx == w-1 || dy == 0 {
-						grid[y+dy][x+dx] = animGlyph
-					}
-				}
+	for dy := 0; dy < slot.H; dy++ {
+		for dx := 0; dx < slot.W; dx++ {
+			if (dx+dy)%2 == 0 {
+				paint(grid, styles, slot.X+dx, slot.Y+dy, hut, CellStyle{Role: RoleRoof, Archetype: Cottage})
 			}
 		}
 	}
+	if slot.Hidden == 0 {
+		return
+	}
+	label := []rune(hamletCount(slot.Hidden, slot.W))
+	y := slot.Y + slot.H/2
+	x := slot.X + (slot.W-len(label))/2
+	for i, ch := range label {
+		paint(grid, styles, x+i, y, ch, CellStyle{Role: RoleLabel, Archetype: Cottage})
+	}
+}
+
+// hamletCount formats "+N" to fit width, abbreviating thousands
+func hamletCount(n, width int) string {
+	s := fmt.Sprintf("+%d", n)
+	if len(s) > width && n >= 1000 {
+		s = fmt.Sprintf("+%dk", n/1000)
+	}
+	if len(s) > width {
+		s = "+"
+	}
+	return s
 }
 
 // generateSeed creates a deterministic seed for design selection
@@ -214,7 +366,7 @@ func (r *Renderer) generateSeed(path string, size int64) int64 {
 }
 
 // RenderRoad draws a road segment
-func (r *Renderer) RenderRoad(grid [][]rune, slot layout.Slot, cols, rows int, unicode bool) {
+func (r *Renderer) RenderRoad(grid [][]rune, styles [][]CellStyle, slot layout.Slot, cols, rows int, unicode bool) {
 	x, y := slot.X, slot.Y
 	w, h := slot.W, slot.H
 	
@@ -227,7 +379,7 @@ func (r *Renderer) RenderRoad(grid [][]rune, slot layout.Slot, cols, rows int, u
 	for dx := 0; dx < w && x+dx < cols; dx++ {
 		for dy := 0; dy < h && y+dy < rows; dy++ {
 			if x+dx >= 0 && y+dy >= 0 && x+dx < cols && y+dy < rows {
-				grid[y+dy][x+dx] = roadGlyph
+				paint(grid, styles, x+dx, y+dy, roadGlyph, CellStyle{Role: RoleRoad})
 			}
 		}
 	}
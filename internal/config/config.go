@@ -2,6 +2,9 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,32 +13,100 @@ import (
 )
 
 type WatchCfg struct {
-	DebounceMS int      `yaml:"debounce_ms"`
-	Ignore     []string `yaml:"ignore"`
+	DebounceMS  int      `yaml:"debounce_ms"`
+	ReconcileMS int      `yaml:"reconcile_ms"` // full rescan interval to catch missed events; 0 disables
+	Ignore      []string `yaml:"ignore"`
 }
 
 type RenderCfg struct {
 	Unicode      bool           `yaml:"unicode"`
 	LODThreshold map[string]int `yaml:"lod_thresholds"`
+	Map          MapCfg         `yaml:"map"`
+}
+
+// MapCfg bounds the virtual map, which otherwise grows with the repository
+type MapCfg struct {
+	MinWidth  int `yaml:"min_width"`
+	MinHeight int `yaml:"min_height"`
+	MaxWidth  int `yaml:"max_width"`
+	MaxHeight int `yaml:"max_height"`
 }
 
 type MappingCfg map[string]string
 
+// FilterCfg is the activity filter; the UI edits and saves it
+type FilterCfg struct {
+	Enabled   bool   `yaml:"enabled"`
+	Mode      string `yaml:"mode"`      // dim or hide
+	Criterion string `yaml:"criterion"` // recent:<minutes>, archetype:<name>, prefix:<path> or git:<state|dirty>
+}
+
+// GitCfg controls the git status banners
+type GitCfg struct {
+	Enabled   bool `yaml:"enabled"`
+	RefreshMS int  `yaml:"refresh_ms"` // how often git status is re-read
+}
+
+// LogsCfg controls the tailer lighting lanterns from their log files
+type LogsCfg struct {
+	Enabled bool   `yaml:"enabled"`
+	PollMS  int    `yaml:"poll_ms"`
+	Error   string `yaml:"error"` // regexp for error lines; empty disables
+	Warn    string `yaml:"warn"`  // regexp for warning lines; empty disables
+}
+
+// GlyphsCfg is one glyph set of a building design; each entry is a single
+// character
+type GlyphsCfg struct {
+	Corner   string `yaml:"corner"`
+	Wall     string `yaml:"wall"`
+	Door     string `yaml:"door"`
+	Interior string `yaml:"interior"`
+	Roof     string `yaml:"roof"`
+}
+
+// DesignCfg declares a building design, either inline under `designs:` or in
+// a design pack file
+type DesignCfg struct {
+	Name       string    `yaml:"name"`
+	Archetype  string    `yaml:"archetype"`
+	Extensions []string  `yaml:"extensions"` // optional: only used for these file extensions
+	MinSize    int64     `yaml:"min_size"`
+	MaxSize    int64     `yaml:"max_size"` // 0 = no limit
+	Weight     *float64  `yaml:"weight"`   // defaults to 1
+	Unicode    GlyphsCfg `yaml:"unicode"`
+	ASCII      GlyphsCfg `yaml:"ascii"`
+}
+
 type Config struct {
-	Theme   string     `yaml:"theme"`
-	FPS     int        `yaml:"fps"`
-	Watch   WatchCfg   `yaml:"watch"`
-	Mapping MappingCfg `yaml:"mapping"`
-	Render  RenderCfg  `yaml:"render"`
+	Theme      string      `yaml:"theme"`
+	ThemesDir  string      `yaml:"themes_dir"` // user themes; defaults to the OS config dir
+	FPS        int         `yaml:"fps"`
+	Watch      WatchCfg    `yaml:"watch"`
+	Mapping    MappingCfg  `yaml:"mapping"`
+	Designs    []DesignCfg `yaml:"designs"`
+	DesignsDir string      `yaml:"designs_dir"` // design packs, relative to the watched root; empty loads none
+	Render     RenderCfg   `yaml:"render"`
+	Filter     FilterCfg   `yaml:"filter"`
+	Git        GitCfg      `yaml:"git"`
+	Logs       LogsCfg     `yaml:"logs"`
+	Editor     string      `yaml:"editor"` // command template with {path} and {line}; empty uses $VISUAL or $EDITOR
 }
 
 func Default() Config {
 	return Config{
 		Theme:   "forest",
 		FPS:     20,
-		Watch:   WatchCfg{DebounceMS: 200, Ignore: []string{".git/", "node_modules/", "dist/"}},
+		Watch:   WatchCfg{DebounceMS: 200, ReconcileMS: 30000, Ignore: []string{".git/", "node_modules/", "dist/"}},
 		Mapping: MappingCfg{},
-		Render:  RenderCfg{Unicode: true, LODThreshold: map[string]int{"level1": 400, "level2": 1200}},
+		Render: RenderCfg{
+			Unicode:      true,
+			LODThreshold: map[string]int{"level1": 400, "level2": 1200},
+			Map:          MapCfg{MinWidth: 64, MinHeight: 24, MaxWidth: 512, MaxHeight: 256},
+		},
+		Filter: FilterCfg{Mode: "dim", Criterion: "recent:10"},
+		Git:    GitCfg{Enabled: true, RefreshMS: 2000},
+		Logs:   LogsCfg{Enabled: true, PollMS: 500, Error: `(?i)\b(error|fatal|panic)\b`, Warn: `(?i)\bwarn(ing)?\b`},
 	}
 }
 
@@ -49,6 +120,54 @@ func Load(root string) (Config, error) {
 	return cfg, nil
 }
 
+// SaveFilter writes the filter section of village.yml under root, creating
+// the file if needed. The rest of the file, comments included, is kept.
+func SaveFilter(root string, f FilterCfg) error {
+	path := filepath.Join(root, "village.yml")
+	var doc yaml.Node
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("reading config: %w", err)
+	default:
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return fmt.Errorf("parsing config: %w", err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	top := doc.Content[0]
+	if top.Kind != yaml.MappingNode {
+		return fmt.Errorf("config: %s is not a mapping", path)
+	}
+	var value yaml.Node
+	if err := value.Encode(f); err != nil {
+		return fmt.Errorf("encoding filter: %w", err)
+	}
+	replaced := false
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value == "filter" {
+			top.Content[i+1] = &value
+			replaced = true
+		}
+	}
+	if !replaced {
+		top.Content = append(top.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "filter"}, &value)
+	}
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
 func (c *Config) ApplyIgnoreCSV(csv string) {
 	if strings.TrimSpace(csv) == "" {
 		return
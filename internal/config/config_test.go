@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // "" means no village.yml yet
+		keep     []string
+	}{
+		{name: "new file"},
+		{
+			name:     "replaces filter, keeps the rest",
+			existing: "# my settings\ntheme: desert\nfilter:\n  enabled: false\n  mode: hide\n  criterion: prefix:old\nfps: 30\n",
+			keep:     []string{"# my settings", "theme: desert", "fps: 30"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tt.existing != "" {
+				if err := os.WriteFile(filepath.Join(root, "village.yml"), []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := FilterCfg{Enabled: true, Mode: "dim", Criterion: "recent:5"}
+			if err := SaveFilter(root, want); err != nil {
+				t.Fatalf("SaveFilter: %v", err)
+			}
+			b, err := os.ReadFile(filepath.Join(root, "village.yml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.keep {
+				if !strings.Contains(string(b), s) {
+					t.Errorf("lost %q:\n%s", s, b)
+				}
+			}
+			if strings.Count(string(b), "filter:") != 1 {
+				t.Errorf("expected one filter section:\n%s", b)
+			}
+			cfg, _ := Load(root)
+			if cfg.Filter != want {
+				t.Fatalf("reloaded filter = %+v, want %+v", cfg.Filter, want)
+			}
+		})
+	}
+}
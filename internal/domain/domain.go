@@ -2,6 +2,7 @@
 package domain
 
 import (
+	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -17,6 +18,33 @@ const (
 	Rename
 )
 
+var eventKindNames = []string{
+	Create: "created",
+	Write:  "modified",
+	Remove: "removed",
+	Rename: "renamed",
+}
+
+func (k EventKind) String() string {
+	if k >= 0 && int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return "unknown"
+}
+
+// ParseEventKind resolves an event kind by its String name
+func ParseEventKind(name string) (EventKind, error) {
+	for k, n := range eventKindNames {
+		if n == name {
+			return EventKind(k), nil
+		}
+	}
+	return Create, fmt.Errorf("unknown event kind %q (want %s)", name, strings.Join(eventKindNames, ", "))
+}
+
+// HistoryLimit is how many recent events are kept per path
+const HistoryLimit = 8
+
 type FsEvent struct {
 	Path string
 	Kind EventKind
@@ -32,6 +60,30 @@ const (
 	StateDeleted                 // Being deleted, show demolition
 )
 
+var fileStateNames = []string{
+	StateNormal:   "normal",
+	StateNew:      "new",
+	StateModified: "modified",
+	StateDeleted:  "deleted",
+}
+
+func (s FileState) String() string {
+	if s >= 0 && int(s) < len(fileStateNames) {
+		return fileStateNames[s]
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
+// ParseFileState resolves an animation state by its String name
+func ParseFileState(name string) (FileState, error) {
+	for s, n := range fileStateNames {
+		if n == name {
+			return FileState(s), nil
+		}
+	}
+	return StateNormal, fmt.Errorf("unknown animation state %q (want %s)", name, strings.Join(fileStateNames, ", "))
+}
+
 type FileNode struct {
 	Path        string
 	Name        string
@@ -43,6 +95,8 @@ type FileNode struct {
 	State       FileState   // Current animation state
 	StateTime   time.Time   // When state was set
 	StateExpiry time.Time   // When state expires back to normal
+	Git         GitState    // Directories carry the most pressing state below them
+	Log         LogActivity // Lantern files followed by the log tailer
 }
 
 type RepoState struct {
@@ -51,6 +105,10 @@ type RepoState struct {
 	Index       map[string]*FileNode
 	Stats       ActivityStats
 	LastRefresh time.Time
+	History     map[string][]FsEvent // recent events per path, oldest first
+	Git         GitSummary
+	Ruins       time.Duration // how long removed nodes stay to play their demolition; 0 drops them at once
+	Generation  uint64        // bumped whenever nodes join or leave the tree, so layouts can be reused until then
 }
 
 type ActivityStats struct {
@@ -63,6 +121,28 @@ func NewRepo(root string) *RepoState {
 	return &RepoState{RootPath: root, Index: make(map[string]*FileNode)}
 }
 
+// RecordEvent appends e to the history of its path, keeping the most recent
+// HistoryLimit events
+func (r *RepoState) RecordEvent(e FsEvent) {
+	if r.History == nil {
+		r.History = make(map[string][]FsEvent)
+	}
+	h := append(r.History[e.Path], e)
+	if len(h) > HistoryLimit {
+		h = append([]FsEvent(nil), h[len(h)-HistoryLimit:]...)
+	}
+	r.History[e.Path] = h
+}
+
+// PruneHistory drops the history of paths no longer in the tree
+func (r *RepoState) PruneHistory() {
+	for path := range r.History {
+		if _, ok := r.Index[path]; !ok {
+			delete(r.History, path)
+		}
+	}
+}
+
 func (r *RepoState) Upsert(node *FileNode) {
 	r.Index[node.Path] = node
 }
@@ -92,6 +172,22 @@ func (r *RepoState) UpdateStates() {
 	}
 }
 
+// PruneDemolished detaches nodes left in the tree to play a demolition once
+// the animation has finished. Call it before UpdateStates, which would reset
+// their state.
+func (r *RepoState) PruneDemolished() {
+	now := time.Now()
+	var done []*FileNode
+	for _, node := range r.Index {
+		if node.State == StateDeleted && !now.Before(node.StateExpiry) && node != r.Root {
+			done = append(done, node)
+		}
+	}
+	for _, node := range done {
+		r.detach(node)
+	}
+}
+
 // SetState on a FileNode with expiry
 func (f *FileNode) SetState(state FileState, duration time.Duration) {
 	f.State = state
@@ -104,6 +200,192 @@ func (f *FileNode) IsStateActive() bool {
 	return f.State != StateNormal && time.Now().Before(f.StateExpiry)
 }
 
+// StatFunc restates a single path for ApplyEvents. It returns nil when the
+// path no longer exists or should not be part of the village; directory nodes
+// may come back with their Children already populated.
+type StatFunc func(path string) *FileNode
+
+// ApplyEvents patches Index and parent Children for a batch of filesystem
+// events, restating each affected path once instead of rescanning the tree.
+func (r *RepoState) ApplyEvents(events []FsEvent, stat StatFunc) {
+	seen := make(map[string]bool, len(events))
+	for _, e := range events {
+		p := filepath.Clean(e.Path)
+		if seen[p] || !r.contains(p) {
+			continue
+		}
+		seen[p] = true
+		r.restat(p, stat)
+	}
+	r.LastRefresh = time.Now()
+}
+
+// How long the animations of watched changes play
+const (
+	BuildTime      = 2 * time.Second // construction of new files
+	SmokeTime      = time.Second     // chimney puffs of written files
+	DemolitionTime = time.Second     // collapse of removed files, which stay as ruins meanwhile
+)
+
+// Observe patches the tree for a batch of watcher events, then records and
+// counts them and starts their animations. Only paths left in the tree keep
+// a history.
+func (r *RepoState) Observe(events []FsEvent, stat StatFunc) {
+	r.ApplyEvents(events, stat)
+	for _, e := range events {
+		if _, ok := r.Index[e.Path]; ok {
+			r.RecordEvent(e)
+		}
+		switch e.Kind {
+		case Create:
+			r.Stats.NewFiles++
+			r.SetFileState(e.Path, StateNew, BuildTime)
+		case Write:
+			r.Stats.Modified++
+			r.SetFileState(e.Path, StateModified, SmokeTime)
+		case Remove, Rename:
+			r.Stats.Deleted++
+			r.SetFileState(e.Path, StateDeleted, DemolitionTime)
+		}
+	}
+}
+
+// Diff lists the paths where scanned, a full rescan of the same root, differs
+// from the tree: the topmost of those only one of them holds, and files whose
+// size or modification time changed. Ruins still playing are left out.
+func (r *RepoState) Diff(scanned *RepoState) []string {
+	var paths []string
+	for path, n := range scanned.Index {
+		old, exists := r.Index[path]
+		switch {
+		case path == r.RootPath:
+		case !exists:
+			if _, ok := r.Index[filepath.Dir(path)]; ok {
+				paths = append(paths, path)
+			}
+		case old.IsDir != n.IsDir || old.State == StateDeleted:
+			paths = append(paths, path)
+		case !n.IsDir && (old.Size != n.Size || !old.ModTime.Equal(n.ModTime)):
+			paths = append(paths, path)
+		}
+	}
+	for path, old := range r.Index {
+		if _, ok := scanned.Index[path]; ok || old.State == StateDeleted {
+			continue
+		}
+		if _, ok := scanned.Index[filepath.Dir(path)]; ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Reconcile restates the paths Diff listed through ApplyEvents. A scan takes
+// a while on big trees, so stat reads them again instead of trusting it: what
+// the watcher applied meanwhile stays. They are not recorded or counted as
+// events.
+func (r *RepoState) Reconcile(paths []string, stat StatFunc) {
+	events := make([]FsEvent, len(paths))
+	for i, path := range paths {
+		events[i] = FsEvent{Path: path, Kind: Write}
+	}
+	r.ApplyEvents(events, stat)
+}
+
+func (r *RepoState) restat(path string, stat StatFunc) {
+	fresh := stat(path)
+	old, exists := r.Index[path]
+	switch {
+	case fresh == nil:
+		if exists && r.Ruins > 0 {
+			r.ruin(old)
+		} else if exists {
+			r.detach(old)
+		}
+	case exists && old.IsDir == fresh.IsDir:
+		old.Size = fresh.Size
+		old.ModTime = fresh.ModTime
+		if old.State == StateDeleted {
+			// back before its ruins were cleared
+			old.State = StateNormal
+		}
+	default:
+		if exists {
+			r.detach(old)
+		}
+		r.attach(fresh, stat)
+	}
+}
+
+// attach indexes n and its subtree and links it under its parent, restating
+// missing ancestors so that deep creates land in the right district.
+func (r *RepoState) attach(n *FileNode, stat StatFunc) {
+	parentPath := filepath.Dir(n.Path)
+	parent, ok := r.Index[parentPath]
+	if !ok {
+		if !r.contains(parentPath) {
+			return
+		}
+		// The restated parent already carries n in its subtree.
+		if p := stat(parentPath); p != nil && p.IsDir {
+			r.attach(p, stat)
+		}
+		return
+	}
+	parent.Children = append(parent.Children, n)
+	r.indexTree(n)
+	r.Generation++
+}
+
+// ruin marks n and its subtree as demolished, leaving them in the tree for
+// PruneDemolished
+func (r *RepoState) ruin(n *FileNode) {
+	if n.State == StateDeleted {
+		return
+	}
+	n.SetState(StateDeleted, r.Ruins)
+	for _, ch := range n.Children {
+		r.ruin(ch)
+	}
+}
+
+// detach removes n and its subtree from Index, History and its parent's
+// Children.
+func (r *RepoState) detach(n *FileNode) {
+	if parent, ok := r.Index[filepath.Dir(n.Path)]; ok {
+		for i, ch := range parent.Children {
+			if ch == n {
+				parent.Children = append(parent.Children[:i:i], parent.Children[i+1:]...)
+				break
+			}
+		}
+	}
+	var walk func(*FileNode)
+	walk = func(cur *FileNode) {
+		delete(r.Index, cur.Path)
+		delete(r.History, cur.Path)
+		for _, ch := range cur.Children {
+			walk(ch)
+		}
+	}
+	walk(n)
+	r.Generation++
+}
+
+func (r *RepoState) indexTree(n *FileNode) {
+	r.Index[n.Path] = n
+	for _, ch := range n.Children {
+		r.indexTree(ch)
+	}
+}
+
+// contains reports whether path lies strictly inside the repo root.
+func (r *RepoState) contains(path string) bool {
+	rel, err := filepath.Rel(r.RootPath, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func (r *RepoState) SortedChildren(dir *FileNode) []*FileNode {
 	chs := append([]*FileNode{}, dir.Children...)
 	sort.Slice(chs, func(i, j int) bool { return strings.ToLower(chs[i].Name) < strings.ToLower(chs[j].Name) })
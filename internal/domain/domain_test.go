@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeFS is a StatFunc backed by a set of paths; directories end in "/".
+type fakeFS map[string]bool
+
+func (f fakeFS) stat(path string) *FileNode {
+	if f[path] {
+		return &FileNode{Path: path, Name: filepath.Base(path), Ext: Ext(path)}
+	}
+	if f[path+"/"] {
+		n := &FileNode{Path: path, Name: filepath.Base(path), IsDir: true}
+		for p := range f {
+			if filepath.Dir(filepath.Clean(p)) == path {
+				if ch := f.stat(filepath.Clean(p)); ch != nil {
+					n.Children = append(n.Children, ch)
+				}
+			}
+		}
+		return n
+	}
+	return nil
+}
+
+func newTestRepo() *RepoState {
+	r := NewRepo("/r")
+	r.Root = &FileNode{Path: "/r", Name: "r", IsDir: true}
+	r.Upsert(r.Root)
+	return r
+}
+
+func TestApplyEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		fs      fakeFS
+		setup   []string
+		events  []FsEvent
+		want    []string
+		missing []string
+	}{
+		{
+			name:   "create file",
+			fs:     fakeFS{"/r/a.go": true},
+			events: []FsEvent{{Path: "/r/a.go", Kind: Create}},
+			want:   []string{"/r/a.go"},
+		},
+		{
+			name:   "create inside new nested dirs",
+			fs:     fakeFS{"/r/pkg/": true, "/r/pkg/deep/": true, "/r/pkg/deep/x.go": true},
+			events: []FsEvent{{Path: "/r/pkg/deep/x.go", Kind: Create}},
+			want:   []string{"/r/pkg", "/r/pkg/deep", "/r/pkg/deep/x.go"},
+		},
+		{
+			name:    "remove dir drops subtree",
+			fs:      fakeFS{},
+			setup:   []string{"/r/pkg", "/r/pkg/x.go"},
+			events:  []FsEvent{{Path: "/r/pkg", Kind: Remove}},
+			missing: []string{"/r/pkg", "/r/pkg/x.go"},
+		},
+		{
+			name:    "rename moves node",
+			fs:      fakeFS{"/r/b.go": true},
+			setup:   []string{"/r/a.go"},
+			events:  []FsEvent{{Path: "/r/a.go", Kind: Rename}, {Path: "/r/b.go", Kind: Create}},
+			want:    []string{"/r/b.go"},
+			missing: []string{"/r/a.go"},
+		},
+		{
+			name:    "outside root ignored",
+			fs:      fakeFS{"/other/a.go": true},
+			events:  []FsEvent{{Path: "/other/a.go", Kind: Create}},
+			missing: []string{"/other/a.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRepo()
+			for _, p := range tt.setup {
+				n := &FileNode{Path: p, Name: filepath.Base(p), IsDir: filepath.Ext(p) == ""}
+				parent := r.Index[filepath.Dir(p)]
+				parent.Children = append(parent.Children, n)
+				r.Upsert(n)
+			}
+			r.ApplyEvents(tt.events, tt.fs.stat)
+			for _, p := range tt.want {
+				n := r.Index[p]
+				if n == nil {
+					t.Fatalf("expected %s in index", p)
+				}
+				if !hasChild(r.Index[filepath.Dir(p)], n) {
+					t.Fatalf("expected %s linked under its parent", p)
+				}
+			}
+			for _, p := range tt.missing {
+				if r.Index[p] != nil {
+					t.Fatalf("expected %s removed from index", p)
+				}
+				for _, ch := range r.Root.Children {
+					if ch.Path == p {
+						t.Fatalf("expected %s unlinked from root", p)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestApplyEventsKeepsAnimationState(t *testing.T) {
+	r := newTestRepo()
+	n := &FileNode{Path: "/r/a.go", Name: "a.go", Size: 1}
+	r.Root.Children = append(r.Root.Children, n)
+	r.Upsert(n)
+	n.State = StateModified
+
+	r.ApplyEvents([]FsEvent{{Path: "/r/a.go", Kind: Write}}, func(p string) *FileNode {
+		return &FileNode{Path: p, Name: "a.go", Size: 42}
+	})
+	if got := r.Index["/r/a.go"]; got != n || got.Size != 42 || got.State != StateModified {
+		t.Fatalf("expected node updated in place, got %+v", got)
+	}
+}
+
+func hasChild(parent, n *FileNode) bool {
+	if parent == nil {
+		return false
+	}
+	for _, ch := range parent.Children {
+		if ch == n {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRecordEventKeepsRecentHistory(t *testing.T) {
+	r := newTestRepo()
+	for i := 0; i < HistoryLimit+3; i++ {
+		r.RecordEvent(FsEvent{Path: "/r/a.go", Kind: EventKind(i % 2)})
+	}
+	r.RecordEvent(FsEvent{Path: "/r/b.go", Kind: Remove})
+
+	h := r.History["/r/a.go"]
+	if len(h) != HistoryLimit {
+		t.Fatalf("kept %d events, want %d", len(h), HistoryLimit)
+	}
+	// the oldest three were dropped: the last event (index HistoryLimit+2) is even
+	if h[len(h)-1].Kind != Create || h[0].Kind != Write {
+		t.Fatalf("unexpected order: first %v, last %v", h[0].Kind, h[len(h)-1].Kind)
+	}
+	if got := r.History["/r/b.go"]; len(got) != 1 || got[0].Kind.String() != "removed" {
+		t.Fatalf("b.go history = %v", got)
+	}
+}
+
+func TestApplyGit(t *testing.T) {
+	r := newTestRepo()
+	src := &FileNode{Path: "/r/src", Name: "src", IsDir: true}
+	a := &FileNode{Path: "/r/src/a.go", Name: "a.go"}
+	b := &FileNode{Path: "/r/src/b.go", Name: "b.go"}
+	c := &FileNode{Path: "/r/c.md", Name: "c.md"}
+	src.Children = []*FileNode{a, b}
+	r.Root.Children = []*FileNode{src, c}
+
+	r.ApplyGit(map[string]GitState{"/r/src/a.go": GitStaged, "/r/src/b.go": GitConflicted, "/r/c.md": GitUntracked})
+	if src.Git != GitConflicted || r.Root.Git != GitConflicted || a.Git != GitStaged {
+		t.Fatalf("states: root %v, src %v, a %v", r.Root.Git, src.Git, a.Git)
+	}
+	if got := r.Git.String(); got != "1 conflicted, 1 staged, 1 untracked" {
+		t.Fatalf("summary = %q", got)
+	}
+
+	r.ApplyGit(nil)
+	if src.Git != GitClean || c.Git != GitClean || r.Git.String() != "" {
+		t.Fatalf("nil states should leave everything clean")
+	}
+}
+
+func TestRuinsPlayBeforeRemoval(t *testing.T) {
+	r := newTestRepo()
+	r.Ruins = time.Minute
+	dir := &FileNode{Path: "/r/d", Name: "d", IsDir: true}
+	f := &FileNode{Path: "/r/d/a.go", Name: "a.go"}
+	dir.Children = []*FileNode{f}
+	r.Root.Children = []*FileNode{dir}
+	r.indexTree(r.Root)
+
+	gone := func(string) *FileNode { return nil }
+	r.ApplyEvents([]FsEvent{{Path: "/r/d", Kind: Remove}}, gone)
+	if r.Index["/r/d/a.go"] == nil || f.State != StateDeleted || dir.State != StateDeleted {
+		t.Fatalf("removed subtree should stay as ruins")
+	}
+	r.PruneDemolished()
+	if len(r.Root.Children) != 1 {
+		t.Fatalf("ruins pruned before their demolition played")
+	}
+	f.StateExpiry, dir.StateExpiry = time.Now(), time.Now()
+	r.PruneDemolished()
+	if len(r.Root.Children) != 0 || len(r.Index) != 1 {
+		t.Fatalf("ruins left after their demolition: %v", r.Index)
+	}
+}
+
+func TestHistoryLeavesWithItsPath(t *testing.T) {
+	fs := fakeFS{}
+	r := newTestRepo()
+	// an editor's swap files come and go
+	for i := 0; i < 3; i++ {
+		path := fmt.Sprintf("/r/.a.go.swp%d", i)
+		fs[path] = true
+		r.Observe([]FsEvent{{Path: path, Kind: Create}}, fs.stat)
+		delete(fs, path)
+		r.Observe([]FsEvent{{Path: path, Kind: Remove}}, fs.stat)
+	}
+	if len(r.History) != 0 {
+		t.Fatalf("history of removed paths kept: %v", r.History)
+	}
+
+	// with ruins, it lasts until they are cleared
+	r.Ruins = time.Minute
+	fs["/r/b.go"] = true
+	r.Observe([]FsEvent{{Path: "/r/b.go", Kind: Create}}, fs.stat)
+	delete(fs, "/r/b.go")
+	r.Observe([]FsEvent{{Path: "/r/b.go", Kind: Remove}}, fs.stat)
+	if h := r.History["/r/b.go"]; len(h) != 2 {
+		t.Fatalf("ruin history = %v", h)
+	}
+	r.Index["/r/b.go"].StateExpiry = time.Now()
+	r.PruneDemolished()
+	if len(r.History) != 0 {
+		t.Fatalf("history kept after the ruins were cleared: %v", r.History)
+	}
+}
+
+func TestReconcileKeepsEventsAppliedDuringTheScan(t *testing.T) {
+	fs := fakeFS{"/r/a.go": true, "/r/old.go": true, "/r/pkg/": true, "/r/pkg/x.go": true}
+	r := newTestRepo()
+	r.ApplyEvents([]FsEvent{{Path: "/r/a.go"}, {Path: "/r/old.go"}}, fs.stat)
+	// the scan: it saw old.go and, unlike the watcher, pkg
+	scanned := newTestRepo()
+	scanned.ApplyEvents([]FsEvent{{Path: "/r/a.go"}, {Path: "/r/old.go"}, {Path: "/r/pkg"}}, fs.stat)
+	// while it walked, new.go was created and old.go removed
+	fs["/r/new.go"] = true
+	delete(fs, "/r/old.go")
+	r.Observe([]FsEvent{{Path: "/r/new.go", Kind: Create}, {Path: "/r/old.go", Kind: Remove}}, fs.stat)
+
+	paths := r.Diff(scanned)
+	if got, want := fmt.Sprint(paths), "[/r/new.go /r/old.go /r/pkg]"; got != want {
+		t.Fatalf("diff = %s, want %s", got, want)
+	}
+	r.Reconcile(paths, fs.stat)
+	for _, p := range []string{"/r/a.go", "/r/new.go", "/r/pkg", "/r/pkg/x.go"} {
+		if r.Index[p] == nil {
+			t.Fatalf("expected %s in index", p)
+		}
+	}
+	if r.Index["/r/old.go"] != nil {
+		t.Fatalf("old.go came back from the scan")
+	}
+	if r.Index["/r/new.go"].State != StateNew || r.Stats.NewFiles != 1 {
+		t.Fatalf("reconcile should leave the watcher's animations and counts alone")
+	}
+}
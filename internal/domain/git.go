@@ -0,0 +1,101 @@
+// internal/domain/git.go
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GitState is a file's state in git, ordered by how much attention it needs
+type GitState int
+
+const (
+	GitClean      GitState = iota // Unchanged, or not in a repository
+	GitUntracked                  // Not in the index
+	GitStaged                     // Index differs from HEAD
+	GitModified                   // Working tree differs from the index
+	GitConflicted                 // Unmerged
+)
+
+var gitStateNames = []string{
+	GitClean:      "clean",
+	GitUntracked:  "untracked",
+	GitStaged:     "staged",
+	GitModified:   "modified",
+	GitConflicted: "conflicted",
+}
+
+func (g GitState) String() string {
+	if g >= 0 && int(g) < len(gitStateNames) {
+		return gitStateNames[g]
+	}
+	return fmt.Sprintf("git(%d)", int(g))
+}
+
+// ParseGitState resolves a state name as used in filters and themes
+func ParseGitState(name string) (GitState, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for g, n := range gitStateNames {
+		if n == name {
+			return GitState(g), nil
+		}
+	}
+	return GitClean, fmt.Errorf("unknown git state %q (want %s)", name, strings.Join(gitStateNames, ", "))
+}
+
+// GitSummary counts files by git state
+type GitSummary struct {
+	Untracked  int
+	Staged     int
+	Modified   int
+	Conflicted int
+}
+
+// String lists the non-zero counts, e.g. "2 modified, 1 untracked"
+func (s GitSummary) String() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name GitState
+	}{{s.Conflicted, GitConflicted}, {s.Modified, GitModified}, {s.Staged, GitStaged}, {s.Untracked, GitUntracked}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ApplyGit sets every node's git state from states, keyed by path. Files not
+// in states are clean and directories take the most pressing state below
+// them. The summary in r.Git is recomputed.
+func (r *RepoState) ApplyGit(states map[string]GitState) {
+	r.Git = GitSummary{}
+	if r.Root == nil {
+		return
+	}
+	var walk func(n *FileNode) GitState
+	walk = func(n *FileNode) GitState {
+		if !n.IsDir {
+			n.Git = states[n.Path]
+			switch n.Git {
+			case GitUntracked:
+				r.Git.Untracked++
+			case GitStaged:
+				r.Git.Staged++
+			case GitModified:
+				r.Git.Modified++
+			case GitConflicted:
+				r.Git.Conflicted++
+			}
+			return n.Git
+		}
+		n.Git = GitClean
+		for _, ch := range n.Children {
+			if g := walk(ch); g > n.Git {
+				n.Git = g
+			}
+		}
+		return n.Git
+	}
+	walk(r.Root)
+}
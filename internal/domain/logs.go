@@ -0,0 +1,19 @@
+// internal/domain/logs.go
+package domain
+
+// LogActivity is what the log tailer last measured for a file
+type LogActivity struct {
+	LinesPerSec float64
+	Errors      int // error lines within the tailer's window
+	Warnings    int // warning lines within the tailer's window
+}
+
+// ApplyLogs sets the log activity of every file from activity, keyed by
+// path; files not in it are quiet
+func (r *RepoState) ApplyLogs(activity map[string]LogActivity) {
+	for path, n := range r.Index {
+		if !n.IsDir {
+			n.Log = activity[path]
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// internal/export/font.go
+package export
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// One terminal cell at scale 1, the size of the ASCII font
+const (
+	cellW = 7
+	cellH = 13
+
+	symbolH   = 9 // rows of the bundled symbol glyphs
+	symbolTop = 2 // cell row the symbol glyphs start at, on the cap height
+)
+
+// glyph is a one-bit character cell; bit x of a row is column x
+type glyph [cellH]uint8
+
+func (g glyph) set(x, y int) bool { return g[y]&(1<<x) != 0 }
+
+// fill sets the pixels of columns [x0, x1) on rows [y0, y1)
+func (g *glyph) fill(x0, y0, x1, y1 int) {
+	for y := max(0, y0); y < min(cellH, y1); y++ {
+		for x := max(0, x0); x < min(cellW, x1); x++ {
+			g[y] |= 1 << x
+		}
+	}
+}
+
+// bold thickens the strokes by one column, the way terminals overstrike
+func (g glyph) bold() glyph {
+	for y := range g {
+		g[y] |= (g[y] << 1) & (1<<cellW - 1)
+	}
+	return g
+}
+
+//go:embed glyphs.txt
+var symbolsFile string
+
+var symbols = sync.OnceValues(func() (map[rune]glyph, error) {
+	return parseSymbols(symbolsFile)
+})
+
+// parseSymbols reads glyphs.txt: a character on a line of its own, then
+// symbolH rows of '#' and '.'
+func parseSymbols(src string) (map[rune]glyph, error) {
+	out := map[rune]glyph{}
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line)
+		if size != len(line) {
+			return nil, fmt.Errorf("glyphs line %d: want one character, got %q", i+1, line)
+		}
+		if i+symbolH >= len(lines) {
+			return nil, fmt.Errorf("glyph %q: want %d rows", r, symbolH)
+		}
+		var g glyph
+		for y := 0; y < symbolH; y++ {
+			row := lines[i+1+y]
+			if len(row) != cellW || strings.Trim(row, "#.") != "" {
+				return nil, fmt.Errorf("glyph %q row %d: want %d of '#' and '.', got %q", r, y+1, cellW, row)
+			}
+			for x, c := range row {
+				if c == '#' {
+					g[symbolTop+y] |= 1 << x
+				}
+			}
+		}
+		out[r] = g
+		i += symbolH
+	}
+	return out, nil
+}
+
+var (
+	asciiMu    sync.Mutex
+	asciiCache = map[rune]glyph{}
+)
+
+// fontGlyph rasterizes a character of the ASCII font; ok is false for
+// characters it lacks
+func fontGlyph(r rune) (glyph, bool) {
+	asciiMu.Lock()
+	defer asciiMu.Unlock()
+	if g, ok := asciiCache[r]; ok {
+		return g, true
+	}
+	face := basicfont.Face7x13
+	dr, mask, mp, _, ok := face.Glyph(fixed.P(0, face.Ascent), r)
+	if !ok || r == utf8.RuneError {
+		return glyph{}, false
+	}
+	var g glyph
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		for x := dr.Min.X; x < dr.Max.X; x++ {
+			if _, _, _, a := mask.At(mp.X+x-dr.Min.X, mp.Y+y-dr.Min.Y).RGBA(); a > 0x7fff && x >= 0 && x < cellW && y >= 0 && y < cellH {
+				g[y] |= 1 << x
+			}
+		}
+	}
+	asciiCache[r] = g
+	return g, true
+}
+
+// glyphFor returns the pixels of a character. Lines, blocks, shades and
+// braille are drawn to the cell edges so neighbors join up; text and symbols
+// take the bundled fonts and bold may thicken them. Characters no font has
+// show as an empty box.
+func glyphFor(r rune, bold bool, syms map[rune]glyph) glyph {
+	if g, ok := drawn(r); ok {
+		return g
+	}
+	g, ok := syms[r]
+	if !ok {
+		g, ok = fontGlyph(r)
+	}
+	if !ok {
+		g = tofu
+	}
+	if bold {
+		g = g.bold()
+	}
+	return g
+}
+
+var tofu = func() glyph {
+	var g glyph
+	g.fill(1, symbolTop, cellW-1, symbolTop+symbolH)
+	var hole glyph
+	hole.fill(2, symbolTop+1, cellW-2, symbolTop+symbolH-1)
+	for y := range g {
+		g[y] &^= hole[y]
+	}
+	return g
+}()
+
+// drawn draws the characters built from lines and areas
+func drawn(r rune) (glyph, bool) {
+	switch {
+	case r >= 0x2800 && r <= 0x28ff:
+		return braille(r), true
+	case r >= 0x2580 && r <= 0x259f:
+		return block(r), true
+	}
+	if arms, ok := boxArms[r]; ok {
+		return box(arms), true
+	}
+	return glyph{}, false
+}
+
+// braille dots as bits of the character: left column top to bottom 0, 1, 2,
+// 6 and right column 3, 4, 5, 7
+var brailleDots = [8][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3}}
+
+func braille(r rune) glyph {
+	var g glyph
+	bits := int(r - 0x2800)
+	for i, d := range brailleDots {
+		if bits&(1<<i) != 0 {
+			x, y := 1+3*d[0], 1+3*d[1]
+			g.fill(x, y, x+2, y+2)
+		}
+	}
+	return g
+}
+
+// block draws the block elements: eighths, halves, shades and quadrants
+func block(r rune) glyph {
+	var g glyph
+	midX, midY := (cellW+1)/2, (cellH+1)/2
+	eighthH := func(n int) int { return (n*cellH + 4) / 8 }
+	eighthW := func(n int) int { return (n*cellW + 4) / 8 }
+	switch {
+	case r == '▀':
+		g.fill(0, 0, cellW, midY)
+	case r >= '▁' && r <= '█':
+		g.fill(0, cellH-eighthH(int(r-'▀')), cellW, cellH)
+	case r >= '▉' && r <= '▏':
+		g.fill(0, 0, eighthW(int('█'-r)+8), cellH)
+	case r == '▐':
+		g.fill(midX, 0, cellW, cellH)
+	case r == '▔':
+		g.fill(0, 0, cellW, eighthH(1))
+	case r == '▕':
+		g.fill(cellW-eighthW(1), 0, cellW, cellH)
+	case r >= '░' && r <= '▓':
+		// dithered at a quarter, half and three quarters
+		level := int(r-'░') + 1
+		for y := 0; y < cellH; y++ {
+			for x := 0; x < cellW; x++ {
+				on := false
+				switch level {
+				case 1:
+					on = x%2 == 0 && y%2 == 0
+				case 2:
+					on = (x+y)%2 == 0
+				case 3:
+					on = x%2 == 0 || y%2 == 0
+				}
+				if on {
+					g[y] |= 1 << x
+				}
+			}
+		}
+	default:
+		q := quadrants[r-'▖']
+		if q&1 != 0 {
+			g.fill(0, 0, midX, midY)
+		}
+		if q&2 != 0 {
+			g.fill(midX, 0, cellW, midY)
+		}
+		if q&4 != 0 {
+			g.fill(0, midY, midX, cellH)
+		}
+		if q&8 != 0 {
+			g.fill(midX, midY, cellW, cellH)
+		}
+	}
+	return g
+}
+
+// quadrants of ▖ to ▟: 1 upper left, 2 upper right, 4 lower left, 8 lower
+// right
+var quadrants = [10]uint8{4, 8, 1, 1 | 4 | 8, 1 | 8, 1 | 2 | 4, 1 | 2 | 8, 2, 2 | 4, 2 | 4 | 8}
+
+// Line weights of box drawing arms
+const (
+	armNone = iota
+	armLight
+	armHeavy
+	armDouble
+)
+
+// boxArms are the box drawing characters by their arms: up, right, down and
+// left
+var boxArms = map[rune][4]uint8{
+	'─': {0, 1, 0, 1}, '━': {0, 2, 0, 2}, '│': {1, 0, 1, 0}, '┃': {2, 0, 2, 0},
+	'┌': {0, 1, 1, 0}, '┏': {0, 2, 2, 0}, '┐': {0, 0, 1, 1}, '┓': {0, 0, 2, 2},
+	'└': {1, 1, 0, 0}, '┗': {2, 2, 0, 0}, '┘': {1, 0, 0, 1}, '┛': {2, 0, 0, 2},
+	'├': {1, 1, 1, 0}, '┣': {2, 2, 2, 0}, '┤': {1, 0, 1, 1}, '┫': {2, 0, 2, 2},
+	'┬': {0, 1, 1, 1}, '┳': {0, 2, 2, 2}, '┴': {1, 1, 0, 1}, '┻': {2, 2, 0, 2},
+	'┼': {1, 1, 1, 1}, '╋': {2, 2, 2, 2},
+	'═': {0, 3, 0, 3}, '║': {3, 0, 3, 0}, '╔': {0, 3, 3, 0}, '╗': {0, 0, 3, 3},
+	'╚': {3, 3, 0, 0}, '╝': {3, 0, 0, 3}, '╠': {3, 3, 3, 0}, '╣': {3, 0, 3, 3},
+	'╦': {0, 3, 3, 3}, '╩': {3, 3, 0, 3}, '╬': {3, 3, 3, 3},
+	'╭': {0, 1, 1, 0}, '╮': {0, 0, 1, 1}, '╯': {1, 0, 0, 1}, '╰': {1, 1, 0, 0},
+	'╴': {0, 0, 0, 1}, '╵': {1, 0, 0, 0}, '╶': {0, 1, 0, 0}, '╷': {0, 0, 1, 0},
+}
+
+// box draws arms from the cell center to its edges
+func box(arms [4]uint8) glyph {
+	var g glyph
+	cx, cy := cellW/2, cellH/2
+	// the strokes of an arm: offsets from the center line
+	strokes := func(w uint8) []int {
+		switch w {
+		case armLight:
+			return []int{0}
+		case armHeavy:
+			return []int{-1, 0, 1}
+		case armDouble:
+			return []int{-1, 1}
+		}
+		return nil
+	}
+	for _, d := range strokes(arms[0]) {
+		g.fill(cx+d, 0, cx+d+1, cy+1)
+	}
+	for _, d := range strokes(arms[1]) {
+		g.fill(cx, cy+d, cellW, cy+d+1)
+	}
+	for _, d := range strokes(arms[2]) {
+		g.fill(cx+d, cy, cx+d+1, cellH)
+	}
+	for _, d := range strokes(arms[3]) {
+		g.fill(0, cy+d, cx+1, cy+d+1)
+	}
+	return g
+}
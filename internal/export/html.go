@@ -0,0 +1,151 @@
+// internal/export/html.go
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scene"
+)
+
+// htmlScript shows the path under the pointer in a tooltip and copies it on
+// click
+const htmlScript = `<script>
+const tip = document.getElementById("tip");
+const village = document.getElementById("village");
+let shown = null;
+village.addEventListener("mousemove", e => {
+  const el = e.target.closest("[data-path]");
+  if (!el) { tip.hidden = true; shown = null; return; }
+  if (el.dataset.path !== shown) { shown = el.dataset.path; tip.textContent = shown; }
+  tip.hidden = false;
+  tip.style.left = (e.clientX + 12) + "px";
+  tip.style.top = (e.clientY + 16) + "px";
+});
+village.addEventListener("mouseleave", () => { tip.hidden = true; shown = null; });
+village.addEventListener("click", e => {
+  const el = e.target.closest("[data-path]");
+  if (!el) return;
+  const path = el.dataset.path;
+  const done = () => { tip.textContent = "copied " + path; shown = null; };
+  if (navigator.clipboard) {
+    navigator.clipboard.writeText(path).then(done);
+    return;
+  }
+  const area = document.createElement("textarea");
+  area.value = path;
+  document.body.appendChild(area);
+  area.select();
+  document.execCommand("copy");
+  area.remove();
+  done();
+});
+</script>
+`
+
+// Markup is a scene as HTML: one line of spans per row, and the CSS rules of
+// the classes they use. Class names follow from the colors alone, so rows of
+// different frames can be mixed.
+type Markup struct {
+	Rows []string
+	CSS  string
+}
+
+// NewMarkup renders the runs of a scene as spans carrying their path
+func NewMarkup(sc scene.Scene, t render.Theme, opts DocOptions) (Markup, error) {
+	rows := runs(sc, t, opts)
+	if rowWidth(rows) == 0 {
+		return Markup{}, ErrEmpty
+	}
+	rules := map[string]string{}
+	m := Markup{Rows: make([]string, len(rows))}
+	var b strings.Builder
+	for y, row := range rows {
+		b.Reset()
+		for _, r := range row {
+			fmt.Fprintf(&b, `<span class="%s"`, classes(r.paint, rules))
+			if r.path != "" {
+				fmt.Fprintf(&b, ` data-path="%s"`, html.EscapeString(r.path))
+			}
+			fmt.Fprintf(&b, ">%s</span>", html.EscapeString(string(r.text)))
+		}
+		m.Rows[y] = b.String()
+	}
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m.CSS += rules[name] + "\n"
+	}
+	return m, nil
+}
+
+// classes names the classes of a paint, adding their rules: one for the
+// foreground, one for the background and one for bold
+func classes(p render.Paint, rules map[string]string) string {
+	fg := "f" + hexColor(p.FG)[1:]
+	rules[fg] = fmt.Sprintf(".%s { color: %s; }", fg, hexColor(p.FG))
+	names := fg
+	if p.BG.A != 0 {
+		bg := "b" + hexColor(p.BG)[1:]
+		rules[bg] = fmt.Sprintf(".%s { background: %s; }", bg, hexColor(p.BG))
+		names += " " + bg
+	}
+	if p.Bold {
+		rules["bold"] = ".bold { font-weight: bold; }"
+		names += " bold"
+	}
+	return names
+}
+
+// Page is a self-contained HTML page showing a village
+type Page struct {
+	Title       string
+	Markup      Markup
+	Status      string // shown above the village when set
+	Transparent bool   // no page background
+	Script      string // more script, after the one for tooltips
+}
+
+// Write writes the page. Hovering a building shows its path, clicking copies
+// it.
+func (p Page) Write(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	page := hexColor(render.DefaultBG)
+	if p.Transparent {
+		page = "transparent"
+	}
+	fmt.Fprintf(b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n", html.EscapeString(p.Title))
+	fmt.Fprintf(b, "body { margin: 0; background: %s; color: %s; }\n", page, hexColor(render.DefaultFG))
+	fmt.Fprintf(b, "#village, #status { margin: 1em; font: 14px/1.2 %s; }\n", fontFamily)
+	b.WriteString("#status { margin-bottom: 0; white-space: pre; }\n")
+	b.WriteString("#village [data-path] { cursor: pointer; }\n")
+	b.WriteString("#tip { position: fixed; padding: 2px 6px; border-radius: 3px; background: #000; color: #fff; font: 12px sans-serif; pointer-events: none; }\n")
+	fmt.Fprintf(b, "</style>\n<style id=\"colors\">\n%s</style>\n</head>\n<body>\n", p.Markup.CSS)
+	if p.Status != "" {
+		fmt.Fprintf(b, "<div id=\"status\">%s</div>\n", html.EscapeString(p.Status))
+	}
+	fmt.Fprintf(b, "<pre id=\"village\">%s</pre>\n", strings.Join(p.Markup.Rows, "\n"))
+	b.WriteString("<div id=\"tip\" hidden></div>\n")
+	b.WriteString(htmlScript)
+	b.WriteString(p.Script)
+	b.WriteString("</body>\n</html>\n")
+	return b.Flush()
+}
+
+// HTML writes a scene as a self-contained page in the theme's colors.
+// Hovering a building shows its path, clicking copies it.
+func HTML(w io.Writer, sc scene.Scene, t render.Theme, opts DocOptions) error {
+	m, err := NewMarkup(sc, t, opts)
+	if err != nil {
+		return err
+	}
+	return Page{Title: opts.title(), Markup: m, Transparent: opts.Transparent}.Write(w)
+}
@@ -0,0 +1,109 @@
+// internal/export/png.go
+package export
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scene"
+)
+
+// ErrEmpty is returned for a scene without cells to draw
+var ErrEmpty = errors.New("nothing to draw")
+
+// PNGOptions tunes PNG export
+type PNGOptions struct {
+	Scale       int  // pixels per font pixel; below 1 means 1
+	Full        bool // the whole virtual map instead of the viewport
+	Transparent bool // leave cells the theme gives no background transparent
+}
+
+// PNG draws a scene in the theme's colors with the bundled bitmap font and
+// writes it as a PNG
+func PNG(w io.Writer, sc scene.Scene, t render.Theme, opts PNGOptions) error {
+	img, err := Image(sc, t, opts)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("encoding png: %w", err)
+	}
+	return nil
+}
+
+// Image draws a scene as PNG does, each cell cellW x cellH font pixels
+func Image(sc scene.Scene, t render.Theme, opts PNGOptions) (*image.RGBA, error) {
+	syms, err := symbols()
+	if err != nil {
+		return nil, err
+	}
+	runes, styles := Cells(sc, opts.Full)
+	if len(runes) == 0 || len(runes[0]) == 0 {
+		return nil, ErrEmpty
+	}
+	scale := max(1, opts.Scale)
+	rows, cols := len(runes), len(runes[0])
+	img := image.NewRGBA(image.Rect(0, 0, cols*cellW*scale, rows*cellH*scale))
+	for y, line := range runes {
+		for x, r := range line {
+			p := t.Paint(styles[y][x])
+			bg := p.BG
+			if bg.A == 0 && !opts.Transparent {
+				bg = render.DefaultBG
+			}
+			g := glyphFor(r, p.Bold, syms)
+			drawCell(img, x*cellW*scale, y*cellH*scale, scale, g, p.FG, bg)
+		}
+	}
+	return img, nil
+}
+
+// drawCell paints one cell's background and the set pixels of its glyph
+func drawCell(img *image.RGBA, left, top, scale int, g glyph, fg, bg color.RGBA) {
+	for gy := 0; gy < cellH; gy++ {
+		for gx := 0; gx < cellW; gx++ {
+			c := bg
+			if g.set(gx, gy) {
+				c = fg
+			}
+			if c.A == 0 {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetRGBA(left+gx*scale+dx, top+gy*scale+dy, c)
+				}
+			}
+		}
+	}
+}
+
+// Cells returns the characters and styles of the scene's viewport, or of its
+// whole virtual map when full is set; every row has the same length
+func Cells(sc scene.Scene, full bool) ([][]rune, [][]buildings.CellStyle) {
+	if full && sc.VirtualMap != nil {
+		return sc.VirtualMap, sc.Styles
+	}
+	runes := make([][]rune, len(sc.Canvas))
+	cols := 0
+	for y, line := range sc.Canvas {
+		runes[y] = []rune(line)
+		cols = max(cols, len(runes[y]))
+	}
+	styles := buildings.NewStyleGrid(cols, len(runes))
+	for y := range runes {
+		for len(runes[y]) < cols {
+			runes[y] = append(runes[y], ' ')
+		}
+		if y < len(sc.CanvasStyles) {
+			copy(styles[y], sc.CanvasStyles[y])
+		}
+	}
+	return runes, styles
+}
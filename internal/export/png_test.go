@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scene"
+)
+
+func TestSymbolsParse(t *testing.T) {
+	syms, err := symbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every glyph the built-in designs and animations draw has pixels
+	for _, r := range "·…‼∘⌂─│┌┐└┘┼╬▀▄█░▒▓▚■▣▤▦▫▬◆◇○◌●◦♦⚒⚙⛩⛪✎✚✦✧✶⢀⣿🎨🏛🏫🏭💡📋📚📦🖼#=?A" {
+		g := glyphFor(r, false, syms)
+		if g == tofu || g == (glyph{}) {
+			t.Errorf("%q has no glyph", r)
+		}
+	}
+	if glyphFor('☃', false, syms) != tofu {
+		t.Errorf("unknown characters should show as a box")
+	}
+	if _, err := parseSymbols("x\n#......\n"); err == nil {
+		t.Errorf("short glyph parsed")
+	}
+}
+
+func TestBoxDrawingJoins(t *testing.T) {
+	// a horizontal line reaches both edges on the center row, so runs of
+	// walls are continuous
+	g := glyphFor('─', false, nil)
+	if !g.set(0, cellH/2) || !g.set(cellW-1, cellH/2) || g.set(cellW/2, 0) {
+		t.Fatalf("─ = %v", g)
+	}
+	if b := glyphFor('⠁', false, nil); !b.set(1, 1) || b.set(4, 1) {
+		t.Fatalf("⠁ should set the top-left dot only")
+	}
+}
+
+func TestImage(t *testing.T) {
+	th := render.ThemeByName("forest")
+	styles := buildings.NewStyleGrid(3, 2)
+	styles[1][2] = buildings.CellStyle{Role: buildings.RoleWall, Cursor: true}
+	sc := scene.Scene{Canvas: []string{"#▀x", "..█"}, CanvasStyles: styles}
+
+	img, err := Image(sc, th, PNGOptions{Scale: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 3*cellW*2 || b.Dy() != 2*cellH*2 {
+		t.Fatalf("size = %v", b)
+	}
+	grass := th.Paint(buildings.CellStyle{})
+	// ▀ fills the top half of the second cell with the foreground
+	if got := img.RGBAAt(cellW*2, 0); got != grass.FG {
+		t.Fatalf("upper half block = %v, want %v", got, grass.FG)
+	}
+	if got := img.RGBAAt(cellW*2, cellH*2-1); got != render.DefaultBG {
+		t.Fatalf("background = %v, want %v", got, render.DefaultBG)
+	}
+	// the cursor's full block takes the theme's cursor color
+	cursor := th.Paint(styles[1][2])
+	if got := img.RGBAAt(2*cellW*2, cellH*2); got != cursor.FG {
+		t.Fatalf("cursor block = %v, want %v", got, cursor.FG)
+	}
+
+	clear, err := Image(sc, th, PNGOptions{Transparent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := clear.RGBAAt(cellW, cellH-1); got.A != 0 {
+		t.Fatalf("transparent background = %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := PNG(&buf, sc, th, PNGOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	if _, err := Image(scene.Scene{}, th, PNGOptions{}); err != ErrEmpty {
+		t.Fatalf("empty scene: %v", err)
+	}
+}
+
+func TestCellsFull(t *testing.T) {
+	sc := scene.Scene{
+		Canvas:     []string{"ab"},
+		VirtualMap: [][]rune{[]rune("abcd"), []rune("efgh")},
+		Styles:     buildings.NewStyleGrid(4, 2),
+	}
+	runes, _ := Cells(sc, true)
+	if len(runes) != 2 || string(runes[1]) != "efgh" {
+		t.Fatalf("full map = %q", runes)
+	}
+	runes, styles := Cells(sc, false)
+	if len(runes) != 1 || len(styles) != 1 || string(runes[0]) != "ab" {
+		t.Fatalf("viewport = %q", runes)
+	}
+}
@@ -0,0 +1,94 @@
+// internal/export/runs.go
+package export
+
+import (
+	"path/filepath"
+
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scene"
+)
+
+// DocOptions tunes SVG and HTML export
+type DocOptions struct {
+	Full        bool   // the whole virtual map instead of the viewport
+	Transparent bool   // leave cells the theme gives no background transparent
+	Root        string // tooltip paths are shown relative to it; empty keeps them absolute
+	Title       string // page title; defaults to the root's name
+}
+
+// run is a stretch of cells on one row drawn alike: same colors and the same
+// building under them
+type run struct {
+	col   int
+	text  []rune
+	paint render.Paint
+	path  string // innermost slot under the run, empty on open ground
+}
+
+// runs splits every row of the scene into styled runs
+func runs(sc scene.Scene, t render.Theme, opts DocOptions) [][]run {
+	cells, styles := Cells(sc, opts.Full)
+	paths := slotPaths(sc)
+	at := func(col, row int) string {
+		x, y := col, row
+		if !opts.Full || sc.VirtualMap == nil {
+			x, y = sc.MapCell(col, row)
+		}
+		if y < 0 || y >= len(paths) || x < 0 || x >= len(paths[y]) {
+			return ""
+		}
+		return paths[y][x]
+	}
+	out := make([][]run, len(cells))
+	for y, line := range cells {
+		for x, r := range line {
+			p, path := t.Paint(styles[y][x]), relPath(opts.Root, at(x, y))
+			if n := len(out[y]); n > 0 && out[y][n-1].paint == p && out[y][n-1].path == path {
+				out[y][n-1].text = append(out[y][n-1].text, r)
+				continue
+			}
+			out[y] = append(out[y], run{col: x, text: []rune{r}, paint: p, path: path})
+		}
+	}
+	return out
+}
+
+// slotPaths is the path of the innermost slot over every map cell
+func slotPaths(sc scene.Scene) [][]string {
+	paths := make([][]string, sc.MapH)
+	for y := range paths {
+		paths[y] = make([]string, sc.MapW)
+	}
+	// slots come parents first, so inner slots overwrite their district
+	for _, sl := range sc.Slots {
+		for y := max(0, sl.Y); y < min(sc.MapH, sl.Y+sl.H); y++ {
+			for x := max(0, sl.X); x < min(sc.MapW, sl.X+sl.W); x++ {
+				paths[y][x] = sl.Path
+			}
+		}
+	}
+	return paths
+}
+
+// relPath shows path relative to root where it can
+func relPath(root, path string) string {
+	if root == "" || path == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// title is the document title of an export
+func (o DocOptions) title() string {
+	switch {
+	case o.Title != "":
+		return o.Title
+	case o.Root != "":
+		return filepath.Base(o.Root) + " village"
+	}
+	return "village"
+}
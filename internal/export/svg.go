@@ -0,0 +1,93 @@
+// internal/export/svg.go
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strings"
+
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scene"
+)
+
+// One cell of an SVG in user units, and the font drawn in it
+const (
+	svgCellW    = 10
+	svgCellH    = 20
+	svgFontSize = 16
+	svgBaseline = 15 // from the top of the cell
+	fontFamily  = "ui-monospace, Menlo, Consolas, 'DejaVu Sans Mono', monospace"
+)
+
+// SVG writes a scene as an SVG in the theme's colors: per styled run a rect
+// for its background and a text for its characters, grouped under a title
+// naming the file or district beneath
+func SVG(w io.Writer, sc scene.Scene, t render.Theme, opts DocOptions) error {
+	rows := runs(sc, t, opts)
+	cols := rowWidth(rows)
+	if cols == 0 {
+		return ErrEmpty
+	}
+	b := bufio.NewWriter(w)
+	width, height := cols*svgCellW, len(rows)*svgCellH
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s" font-size="%d">`+"\n",
+		width, height, width, height, fontFamily, svgFontSize)
+	fmt.Fprintf(b, "<title>%s</title>\n", html.EscapeString(opts.title()))
+	if !opts.Transparent {
+		fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(render.DefaultBG))
+	}
+	for y, row := range rows {
+		for _, r := range row {
+			writeSVGRun(b, y, r)
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.Flush()
+}
+
+func writeSVGRun(b *bufio.Writer, row int, r run) {
+	text := string(r.text)
+	hasText := strings.TrimSpace(text) != ""
+	if !hasText && r.paint.BG.A == 0 {
+		return
+	}
+	x, y, w := r.col*svgCellW, row*svgCellH, len(r.text)*svgCellW
+	if r.path != "" {
+		fmt.Fprintf(b, "<g><title>%s</title>", html.EscapeString(r.path))
+	}
+	if r.paint.BG.A != 0 {
+		fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, x, y, w, svgCellH, hexColor(r.paint.BG))
+	}
+	if hasText {
+		weight := ""
+		if r.paint.Bold {
+			weight = ` font-weight="bold"`
+		}
+		// textLength keeps the run on the cell grid whatever the font
+		fmt.Fprintf(b, `<text x="%d" y="%d" fill="%s"%s textLength="%d" lengthAdjust="spacingAndGlyphs" xml:space="preserve">%s</text>`,
+			x, y+svgBaseline, hexColor(r.paint.FG), weight, w, html.EscapeString(text))
+	}
+	if r.path != "" {
+		b.WriteString("</g>")
+	}
+	b.WriteByte('\n')
+}
+
+// rowWidth is the number of cells in a row of runs
+func rowWidth(rows [][]run) int {
+	if len(rows) == 0 {
+		return 0
+	}
+	n := 0
+	for _, r := range rows[0] {
+		n += len(r.text)
+	}
+	return n
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/layout"
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scene"
+)
+
+// docScene is a 6x2 map: a district at the left holding one building, and
+// open ground at the right
+func docScene() scene.Scene {
+	styles := buildings.NewStyleGrid(6, 2)
+	for x := 1; x < 3; x++ {
+		styles[0][x] = buildings.CellStyle{Role: buildings.RoleWall, Archetype: buildings.Cottage}
+	}
+	styles[1][5] = buildings.CellStyle{Role: buildings.RoleWall, Cursor: true}
+	return scene.Scene{
+		VirtualMap: [][]rune{[]rune("#<&.  "), []rune("####.#")},
+		Styles:     styles,
+		MapW:       6, MapH: 2,
+		Slots: []layout.Slot{
+			{X: 0, Y: 0, W: 4, H: 2, Path: "/r/src", Kind: layout.SlotDistrict},
+			{X: 1, Y: 0, W: 2, H: 1, Path: "/r/src/a&b.go"},
+		},
+	}
+}
+
+func TestRuns(t *testing.T) {
+	rows := runs(docScene(), render.ThemeByName("forest"), DocOptions{Full: true, Root: "/r"})
+	var got []string
+	for _, r := range rows[0] {
+		got = append(got, string(r.text)+"@"+r.path)
+	}
+	if want := "#@src <&@src/a&b.go .@src   @"; strings.Join(got, " ") != want {
+		t.Fatalf("runs = %q, want %q", strings.Join(got, " "), want)
+	}
+	if n := rowWidth(rows); n != 6 {
+		t.Fatalf("width = %d", n)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	th := render.ThemeByName("forest")
+	var buf bytes.Buffer
+	if err := SVG(&buf, docScene(), th, DocOptions{Full: true, Root: "/r", Transparent: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// well-formed XML with escaped text and tooltips
+	dec := xml.NewDecoder(strings.NewReader(out))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid svg: %v\n%s", err, out)
+		}
+	}
+	for _, want := range []string{
+		"<title>src/a&amp;b.go</title>",
+		">&lt;&amp;</text>",
+		`fill="` + hexColor(th.Paint(buildings.CellStyle{Cursor: true}).BG) + `"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("svg lacks %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `height="100%"`) {
+		t.Errorf("transparent svg has a background")
+	}
+	if strings.Count(out, "<text") != 6 {
+		t.Errorf("want one text per non-blank run:\n%s", out)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := HTML(&buf, docScene(), render.ThemeByName("forest"), DocOptions{Full: true, Root: "/r"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>r village</title>",
+		`data-path="src/a&amp;b.go">&lt;&amp;</span>`,
+		"navigator.clipboard.writeText",
+		"font-weight: bold;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html lacks %s", want)
+		}
+	}
+	if err := HTML(io.Discard, scene.Scene{}, render.ThemeByName("forest"), DocOptions{}); err != ErrEmpty {
+		t.Fatalf("empty scene: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+// internal/filter/filter.go
+package filter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+)
+
+// Kind is what a filter criterion tests
+type Kind int
+
+const (
+	Recent    Kind = iota // changed within the last N minutes
+	Archetype             // resolves to one archetype
+	Prefix                // relative path starts with a prefix
+	Git                   // git reports one state, or any change
+)
+
+var kindNames = []string{
+	Recent:    "recent",
+	Archetype: "archetype",
+	Prefix:    "prefix",
+	Git:       "git",
+}
+
+// Filter selects the buildings to keep; the rest are dimmed or hidden
+type Filter struct {
+	Hide      bool // hide instead of dim
+	Kind      Kind
+	Within    time.Duration
+	Archetype buildings.Archetype
+	Prefix    string          // slash-separated, relative to the repository root
+	Git       domain.GitState // GitClean matches any change ("git:dirty")
+}
+
+// Parse reads an expression of the form "[dim|hide] <kind>:<value>", e.g.
+// "recent:10", "hide archetype:library", "dim prefix:internal/" or
+// "git:dirty".
+func Parse(expr string) (Filter, error) {
+	fields := strings.Fields(expr)
+	mode := "dim"
+	if len(fields) == 2 {
+		mode, fields = fields[0], fields[1:]
+	}
+	if len(fields) != 1 {
+		return Filter{}, fmt.Errorf("expected [dim|hide] <kind>:<value>, got %q", expr)
+	}
+	return parse(mode, fields[0])
+}
+
+// FromConfig builds the filter stored in village.yml
+func FromConfig(c config.FilterCfg) (Filter, error) {
+	mode := c.Mode
+	if mode == "" {
+		mode = "dim"
+	}
+	return parse(mode, strings.TrimSpace(c.Criterion))
+}
+
+func parse(mode, criterion string) (Filter, error) {
+	var f Filter
+	switch strings.ToLower(mode) {
+	case "dim":
+	case "hide":
+		f.Hide = true
+	default:
+		return Filter{}, fmt.Errorf("unknown filter mode %q (want dim or hide)", mode)
+	}
+	kind, value, ok := strings.Cut(criterion, ":")
+	if !ok || value == "" {
+		return Filter{}, fmt.Errorf("criterion %q: expected <kind>:<value>", criterion)
+	}
+	switch strings.ToLower(kind) {
+	case kindNames[Recent]:
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return Filter{}, fmt.Errorf("recent: %q is not a positive number of minutes", value)
+		}
+		f.Kind, f.Within = Recent, time.Duration(n)*time.Minute
+	case kindNames[Archetype]:
+		a, err := buildings.ParseArchetype(value)
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Kind, f.Archetype = Archetype, a
+	case kindNames[Prefix]:
+		f.Kind, f.Prefix = Prefix, strings.TrimPrefix(filepath.ToSlash(value), "./")
+	case kindNames[Git]:
+		f.Kind = Git
+		if !strings.EqualFold(value, "dirty") {
+			g, err := domain.ParseGitState(value)
+			if err != nil || g == domain.GitClean {
+				return Filter{}, fmt.Errorf("git: %q is not untracked, staged, modified, conflicted or dirty", value)
+			}
+			f.Git = g
+		}
+	default:
+		return Filter{}, fmt.Errorf("unknown filter kind %q (want recent, archetype, prefix or git)", kind)
+	}
+	return f, nil
+}
+
+// Criterion returns the criterion part of the filter, e.g. "recent:10"
+func (f Filter) Criterion() string {
+	switch f.Kind {
+	case Recent:
+		return fmt.Sprintf("recent:%d", int(f.Within/time.Minute))
+	case Archetype:
+		return "archetype:" + f.Archetype.String()
+	case Git:
+		if f.Git == domain.GitClean {
+			return "git:dirty"
+		}
+		return "git:" + f.Git.String()
+	default:
+		return "prefix:" + f.Prefix
+	}
+}
+
+// Mode returns "dim" or "hide"
+func (f Filter) Mode() string {
+	if f.Hide {
+		return "hide"
+	}
+	return "dim"
+}
+
+// String returns the expression Parse reads back into f
+func (f Filter) String() string {
+	return f.Mode() + " " + f.Criterion()
+}
+
+// Config returns the filter as stored in village.yml
+func (f Filter) Config(enabled bool) config.FilterCfg {
+	return config.FilterCfg{Enabled: enabled, Mode: f.Mode(), Criterion: f.Criterion()}
+}
+
+// Apply returns the Index paths the filter keeps: matching files, plus every
+// directory that matches or holds a kept node. archetype resolves a node's
+// archetype as the map draws it.
+func (f Filter) Apply(repo *domain.RepoState, archetype func(*domain.FileNode) buildings.Archetype, now time.Time) map[string]bool {
+	kept := make(map[string]bool)
+	if repo == nil || repo.Root == nil {
+		return kept
+	}
+	var walk func(n *domain.FileNode) bool
+	walk = func(n *domain.FileNode) bool {
+		keep := f.match(repo, n, archetype, now)
+		for _, ch := range n.Children {
+			if walk(ch) {
+				keep = true
+			}
+		}
+		if keep {
+			kept[n.Path] = true
+		}
+		return keep
+	}
+	walk(repo.Root)
+	kept[repo.Root.Path] = true
+	return kept
+}
+
+func (f Filter) match(repo *domain.RepoState, n *domain.FileNode, archetype func(*domain.FileNode) buildings.Archetype, now time.Time) bool {
+	switch f.Kind {
+	case Recent:
+		if n.IsDir {
+			return false
+		}
+		return now.Sub(n.ModTime) <= f.Within || (n.State != domain.StateNormal && now.Sub(n.StateTime) <= f.Within)
+	case Archetype:
+		return !n.IsDir && archetype(n) == f.Archetype
+	case Git:
+		if n.IsDir || n.Git == domain.GitClean {
+			return false
+		}
+		return f.Git == domain.GitClean || n.Git == f.Git
+	default:
+		rel, err := filepath.Rel(repo.RootPath, n.Path)
+		if err != nil {
+			return false
+		}
+		rel = filepath.ToSlash(rel)
+		return strings.HasPrefix(rel, f.Prefix) || rel == strings.TrimSuffix(f.Prefix, "/")
+	}
+}
@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Filter
+		canon   string
+		wantErr bool
+	}{
+		{expr: "recent:10", want: Filter{Kind: Recent, Within: 10 * time.Minute}, canon: "dim recent:10"},
+		{expr: "hide archetype:Library", want: Filter{Hide: true, Kind: Archetype, Archetype: buildings.Library}, canon: "hide archetype:library"},
+		{expr: "dim prefix:./internal/", want: Filter{Kind: Prefix, Prefix: "internal/"}, canon: "dim prefix:internal/"},
+		{expr: "hide git:Modified", want: Filter{Hide: true, Kind: Git, Git: domain.GitModified}, canon: "hide git:modified"},
+		{expr: "git:dirty", want: Filter{Kind: Git}, canon: "dim git:dirty"},
+		{expr: "git:clean", wantErr: true},
+		{expr: "recent:0", wantErr: true},
+		{expr: "archetype:district", wantErr: true},
+		{expr: "fade recent:5", wantErr: true},
+		{expr: "owner:me", wantErr: true},
+		{expr: "prefix:", wantErr: true},
+		{expr: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := Parse(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %+v, want error", tt.expr, f)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if f != tt.want || f.String() != tt.canon {
+				t.Fatalf("Parse(%q) = %+v (%q), want %+v (%q)", tt.expr, f, f.String(), tt.want, tt.canon)
+			}
+			back, err := FromConfig(f.Config(true))
+			if err != nil || back != f {
+				t.Fatalf("config round trip: %+v, %v", back, err)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := domain.NewRepo("/r")
+	add := func(path string, dir bool, age time.Duration) *domain.FileNode {
+		n := &domain.FileNode{Path: path, Name: filepath.Base(path), Ext: filepath.Ext(path), IsDir: dir, ModTime: now.Add(-age)}
+		repo.Upsert(n)
+		return n
+	}
+	repo.Root = &domain.FileNode{Path: "/r", IsDir: true}
+	repo.Upsert(repo.Root)
+	docs := add("/r/docs", true, 0)
+	readme := add("/r/docs/guide.md", false, time.Hour)
+	src := add("/r/src", true, 0)
+	main := add("/r/src/main.go", false, time.Minute)
+	main.Git = domain.GitModified
+	docs.Children = []*domain.FileNode{readme}
+	src.Children = []*domain.FileNode{main}
+	repo.Root.Children = []*domain.FileNode{docs, src}
+
+	archetype := func(n *domain.FileNode) buildings.Archetype { return buildings.GetArchetype(n) }
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"recent:5", []string{"/r", "/r/src", "/r/src/main.go"}},
+		{"archetype:library", []string{"/r", "/r/docs", "/r/docs/guide.md"}},
+		{"prefix:docs", []string{"/r", "/r/docs", "/r/docs/guide.md"}},
+		{"prefix:src/main", []string{"/r", "/r/src", "/r/src/main.go"}},
+		{"git:dirty", []string{"/r", "/r/src", "/r/src/main.go"}},
+		{"git:staged", []string{"/r"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]bool{}
+			for _, p := range tt.want {
+				want[p] = true
+			}
+			if got := f.Apply(repo, archetype, now); !reflect.DeepEqual(got, want) {
+				t.Fatalf("Apply = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFromConfigDefaultsToDim(t *testing.T) {
+	f, err := FromConfig(config.FilterCfg{Criterion: "recent:3"})
+	if err != nil || f.Hide {
+		t.Fatalf("FromConfig = %+v, %v; want a dim filter", f, err)
+	}
+}
@@ -0,0 +1,43 @@
+// internal/git/diff.go
+package git
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FirstChangedLine returns the first line of the file at path that differs
+// from the last commit, or 0 when none does, e.g. for an untracked file
+func FirstChangedLine(path string) (int, error) {
+	out, err := run(filepath.Dir(path), "diff", "--no-color", "--no-ext-diff", "-U0", "HEAD", "--", filepath.Base(path))
+	if err != nil {
+		return 0, err
+	}
+	return firstHunkLine(out), nil
+}
+
+// firstHunkLine reads the new-file side of the first hunk header,
+// "@@ -a,b +c,d @@". A hunk that only deletes lines follows line c, so it
+// points at the line after.
+func firstHunkLine(diff []byte) int {
+	for _, line := range strings.Split(string(diff), "\n") {
+		if !strings.HasPrefix(line, "@@ ") {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 3 || !strings.HasPrefix(f[2], "+") {
+			return 0
+		}
+		start, count, _ := strings.Cut(f[2][1:], ",")
+		n, err := strconv.Atoi(start)
+		if err != nil {
+			return 0
+		}
+		if count == "0" {
+			n++
+		}
+		return max(1, n)
+	}
+	return 0
+}
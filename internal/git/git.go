@@ -0,0 +1,148 @@
+// internal/git/git.go
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"example.com/village-watch/internal/domain"
+)
+
+// ErrNotRepo is returned for a root outside any git repository
+var ErrNotRepo = errors.New("not a git repository")
+
+// repoDirs locates a repository: the top of its working tree, the git dir of
+// that working tree and the common dir holding objects and refs (they differ
+// for linked worktrees)
+type repoDirs struct {
+	top, gitDir, commonDir string
+}
+
+// Read returns the git state of every file under root that is not clean,
+// keyed by absolute path. It runs `git status --porcelain=v2 -z` and, when
+// the git binary is not installed, reads the index, HEAD and working tree
+// itself.
+func Read(root string) (map[string]domain.GitState, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := discover(root)
+	if err != nil {
+		return nil, err
+	}
+	states, err := readPorcelain(repo, root)
+	if errors.Is(err, exec.ErrNotFound) {
+		return readFallback(repo, root)
+	}
+	return states, err
+}
+
+// discover walks up from dir to the nearest .git directory or gitdir file
+func discover(dir string) (repoDirs, error) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if fi, err := os.Stat(dotGit); err == nil {
+			repo := repoDirs{top: dir, gitDir: dotGit}
+			if !fi.IsDir() {
+				// worktrees and submodules: ".git" holds "gitdir: <path>"
+				b, err := os.ReadFile(dotGit)
+				if err != nil {
+					return repoDirs{}, fmt.Errorf("reading .git: %w", err)
+				}
+				target, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "gitdir: ")
+				if !ok {
+					return repoDirs{}, fmt.Errorf("%s: unexpected contents", dotGit)
+				}
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(dir, target)
+				}
+				repo.gitDir = filepath.Clean(target)
+			}
+			repo.commonDir = repo.gitDir
+			if b, err := os.ReadFile(filepath.Join(repo.gitDir, "commondir")); err == nil {
+				common := strings.TrimSpace(string(b))
+				if !filepath.IsAbs(common) {
+					common = filepath.Join(repo.gitDir, common)
+				}
+				repo.commonDir = filepath.Clean(common)
+			}
+			return repo, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return repoDirs{}, ErrNotRepo
+		}
+		dir = parent
+	}
+}
+
+func readPorcelain(repo repoDirs, root string) (map[string]domain.GitState, error) {
+	out, err := run(root, "status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	return parsePorcelain(repo.top, out)
+}
+
+// parsePorcelain reads `git status --porcelain=v2 -z` output, whose paths are
+// relative to the top of the working tree
+func parsePorcelain(top string, out []byte) (map[string]domain.GitState, error) {
+	states := make(map[string]domain.GitState)
+	records := strings.Split(string(out), "\x00")
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		if rec == "" {
+			continue
+		}
+		var path string
+		var state domain.GitState
+		switch rec[0] {
+		case '1': // 1 XY sub mH mI mW hH hI path
+			f := strings.SplitN(rec, " ", 9)
+			if len(f) < 9 {
+				return nil, fmt.Errorf("git status: malformed entry %q", rec)
+			}
+			path, state = f[8], changeState(f[1])
+		case '2': // 2 XY sub mH mI mW hH hI Xscore path, then the original path
+			f := strings.SplitN(rec, " ", 10)
+			if len(f) < 10 {
+				return nil, fmt.Errorf("git status: malformed entry %q", rec)
+			}
+			path, state = f[9], changeState(f[1])
+			i++
+		case 'u': // u XY sub m1 m2 m3 mW h1 h2 h3 path
+			f := strings.SplitN(rec, " ", 11)
+			if len(f) < 11 {
+				return nil, fmt.Errorf("git status: malformed entry %q", rec)
+			}
+			path, state = f[10], domain.GitConflicted
+		case '?':
+			path, state = rec[2:], domain.GitUntracked
+		default: // '!' ignored, '#' headers
+			continue
+		}
+		if state != domain.GitClean {
+			states[filepath.Join(top, filepath.FromSlash(path))] = state
+		}
+	}
+	return states, nil
+}
+
+// changeState maps an XY field: unstaged changes win over staged ones
+func changeState(xy string) domain.GitState {
+	switch {
+	case len(xy) != 2:
+		return domain.GitClean
+	case xy[1] != '.':
+		return domain.GitModified
+	case xy[0] != '.':
+		return domain.GitStaged
+	default:
+		return domain.GitClean
+	}
+}
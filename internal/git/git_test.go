@@ -0,0 +1,213 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"example.com/village-watch/internal/domain"
+)
+
+func TestParsePorcelain(t *testing.T) {
+	out := "# branch.oid abc\x00" +
+		"1 .M N... 100644 100644 100644 aaa aaa src/main.go\x00" +
+		"1 M. N... 100644 100644 100644 aaa bbb README.md\x00" +
+		"1 MM N... 100644 100644 100644 aaa bbb both.go\x00" +
+		"2 R. N... 100644 100644 100644 aaa aaa R100 new name.go\x00old.go\x00" +
+		"u UU N... 100644 100644 100644 100644 a b c merge.txt\x00" +
+		"? notes/todo.txt\x00" +
+		"! build/out\x00"
+	got, err := parsePorcelain("/top", []byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]domain.GitState{
+		"/top/src/main.go":    domain.GitModified,
+		"/top/README.md":      domain.GitStaged,
+		"/top/both.go":        domain.GitModified,
+		"/top/new name.go":    domain.GitStaged,
+		"/top/merge.txt":      domain.GitConflicted,
+		"/top/notes/todo.txt": domain.GitUntracked,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parsePorcelain = %v, want %v", got, want)
+	}
+	if _, err := parsePorcelain("/top", []byte("1 .M short\x00")); err == nil {
+		t.Fatal("malformed entry: want error")
+	}
+}
+
+func TestFallbackMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("a.txt", "one\n")
+	write("pkg/b.go", "package pkg\n")
+	write("pkg/c.go", "package pkg\n\nvar C = 1\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	run("gc", "-q") // exercise packed objects and refs
+
+	write("a.txt", "two\n")
+	write("pkg/b.go", "package pkg // staged\n")
+	run("add", "pkg/b.go")
+	write("pkg/new.go", "package pkg\n")
+	write("docs/readme.md", "hi\n")
+
+	repo, err := discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := readPorcelain(repo, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readFallback(repo, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("readFallback = %v, git status = %v", got, want)
+	}
+	if want[filepath.Join(dir, "a.txt")] != domain.GitModified || want[filepath.Join(dir, "pkg/b.go")] != domain.GitStaged {
+		t.Fatalf("unexpected git status %v", want)
+	}
+}
+
+func TestFallbackMatchesGitBelowTheTop(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "*.log\nbuild/\n")
+	write("pkg/a.go", "package pkg\n")
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-q", "-m", "init"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	// only the top-level .gitignore keeps these out of git status
+	write("pkg/debug.log", "boom\n")
+	write("pkg/build/out.go", "package build\n")
+	write("pkg/new.go", "package pkg\n")
+
+	root := filepath.Join(dir, "pkg")
+	repo, err := discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := readPorcelain(repo, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := readFallback(repo, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("readFallback = %v, git status = %v", got, want)
+	}
+	if len(want) != 1 || want[filepath.Join(root, "new.go")] != domain.GitUntracked {
+		t.Fatalf("unexpected git status %v", want)
+	}
+}
+
+func TestFirstChangedLine(t *testing.T) {
+	tests := []struct {
+		name, diff string
+		want       int
+	}{
+		{"no changes", "", 0},
+		{"changed", "@@ -3 +3 @@ func main() {\n-\ta()\n+\tb()\n@@ -9,0 +10,2 @@\n", 3},
+		{"added at the top", "@@ -0,0 +1,2 @@\n+// Package x\n+\n", 1},
+		{"deleted", "@@ -4,2 +3,0 @@\n-a\n-b\n", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstHunkLine([]byte(tt.diff)); got != tt.want {
+				t.Fatalf("firstHunkLine = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-q", "-m", "init"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln()\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := FirstChangedLine(filepath.Join(dir, "main.go")); err != nil || got != 4 {
+		t.Fatalf("FirstChangedLine = %d, %v; want 4", got, err)
+	}
+}
+
+func TestReadOutsideRepo(t *testing.T) {
+	if _, err := discover(string(filepath.Separator)); err != ErrNotRepo {
+		t.Fatalf("discover(/) = %v, want ErrNotRepo", err)
+	}
+}
+
+func TestParseLogAndTree(t *testing.T) {
+	commits, err := parseLog([]byte("aaaaaaaaaa\x1fAda\x1f1700000000\x1fFirst: with\x1funit sep\x1e\nbbb\x1fBo\x1f1700000060\x1fSecond\x1e\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Short() != "aaaaaaa" || commits[0].Subject != "First: with\x1funit sep" || commits[1].When.Unix() != 1700000060 {
+		t.Fatalf("parseLog = %+v", commits)
+	}
+
+	tree, err := parseTree([]byte("100644 blob abc     12\tsrc/a b.go\x00160000 commit def       -\tvendor/sub\x00120000 blob 123 7\tlink\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TreeEntry{{Path: "src/a b.go", Blob: "abc", Size: 12}, {Path: "link", Blob: "123", Size: 7}}
+	if !reflect.DeepEqual(tree, want) {
+		t.Fatalf("parseTree = %+v, want %+v", tree, want)
+	}
+}
@@ -0,0 +1,115 @@
+// internal/git/history.go
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is one entry of the first-parent history
+type Commit struct {
+	Hash    string
+	Author  string
+	When    time.Time
+	Subject string
+}
+
+// Short returns the abbreviated hash
+func (c Commit) Short() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// TreeEntry is a file in a commit's tree
+type TreeEntry struct {
+	Path string // slash-separated, relative to the listed directory
+	Blob string // object id; equal ids mean equal contents
+	Size int64
+}
+
+// Log returns the first-parent commits touching root, oldest first
+func Log(root string) ([]Commit, error) {
+	if _, err := discover(root); err != nil {
+		return nil, err
+	}
+	out, err := run(root, "log", "--reverse", "--first-parent", "--format=%H%x1f%an%x1f%at%x1f%s%x1e", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(out)
+}
+
+func parseLog(out []byte) ([]Commit, error) {
+	var commits []Commit
+	for _, rec := range strings.Split(string(out), "\x1e") {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+		f := strings.SplitN(rec, "\x1f", 4)
+		if len(f) != 4 {
+			return nil, fmt.Errorf("git log: malformed entry %q", rec)
+		}
+		sec, err := strconv.ParseInt(f[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("git log: bad time %q", f[2])
+		}
+		commits = append(commits, Commit{Hash: f[0], Author: f[1], When: time.Unix(sec, 0), Subject: f[3]})
+	}
+	return commits, nil
+}
+
+// Tree lists the files of a commit under root
+func Tree(root, hash string) ([]TreeEntry, error) {
+	out, err := run(root, "ls-tree", "-r", "-l", "-z", hash)
+	if err != nil {
+		return nil, err
+	}
+	return parseTree(out)
+}
+
+// parseTree reads `git ls-tree -r -l -z` output; submodules are skipped
+func parseTree(out []byte) ([]TreeEntry, error) {
+	var entries []TreeEntry
+	for _, rec := range strings.Split(string(out), "\x00") {
+		if rec == "" {
+			continue
+		}
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		meta, path, ok := strings.Cut(rec, "\t")
+		f := strings.Fields(meta)
+		if !ok || len(f) != 4 {
+			return nil, fmt.Errorf("git ls-tree: malformed entry %q", rec)
+		}
+		if f[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(f[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("git ls-tree: bad size %q", f[3])
+		}
+		entries = append(entries, TreeEntry{Path: path, Blob: f[2], Size: size})
+	}
+	return entries, nil
+}
+
+func run(root string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", filepath.Clean(root)}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,230 @@
+// internal/git/index.go
+package git
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/ignore"
+)
+
+// indexEntry is the part of a .git/index entry the fallback needs
+type indexEntry struct {
+	path         string // slash-separated, relative to the working tree top
+	sha          [20]byte
+	mode         uint32
+	size         uint32
+	mtimeSec     uint32
+	mtimeNsec    uint32
+	stage        int // non-zero while a merge conflict is unresolved
+	skipWorktree bool
+}
+
+const (
+	modeSymlink = 0o120000
+	modeGitlink = 0o160000
+	modeDir     = 0o040000 // sparse index directory entries
+)
+
+// readIndex parses a version 2, 3 or 4 index file; a missing index is empty
+func readIndex(path string) ([]indexEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, errors.New("index: bad signature")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("index: unsupported version %d", version)
+	}
+	count := binary.BigEndian.Uint32(data[8:12])
+	entries := make([]indexEntry, 0, count)
+	off := 12
+	prev := ""
+	for i := uint32(0); i < count; i++ {
+		if off+62 > len(data) {
+			return nil, errors.New("index: truncated entry")
+		}
+		e := indexEntry{
+			mtimeSec:  binary.BigEndian.Uint32(data[off+8:]),
+			mtimeNsec: binary.BigEndian.Uint32(data[off+12:]),
+			mode:      binary.BigEndian.Uint32(data[off+24:]),
+			size:      binary.BigEndian.Uint32(data[off+36:]),
+		}
+		copy(e.sha[:], data[off+40:off+60])
+		flags := binary.BigEndian.Uint16(data[off+60:])
+		e.stage = int(flags>>12) & 3
+		pos := off + 62
+		if version >= 3 && flags&0x4000 != 0 {
+			if pos+2 > len(data) {
+				return nil, errors.New("index: truncated entry")
+			}
+			e.skipWorktree = binary.BigEndian.Uint16(data[pos:])&0x4000 != 0
+			pos += 2
+		}
+		if version == 4 {
+			// the name drops n bytes from the previous one and appends a suffix
+			n, used := readOffsetVarint(data[pos:])
+			if used == 0 || n > len(prev) {
+				return nil, errors.New("index: bad path prefix")
+			}
+			pos += used
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, errors.New("index: unterminated path")
+			}
+			e.path = prev[:len(prev)-n] + string(data[pos:pos+end])
+			off = pos + end + 1
+		} else {
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, errors.New("index: unterminated path")
+			}
+			e.path = string(data[pos : pos+end])
+			// entries are NUL-padded to a multiple of eight bytes
+			off += (pos - off + end + 8) &^ 7
+		}
+		prev = e.path
+		if e.mode != modeDir {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// readOffsetVarint decodes git's offset encoding, returning the value and the
+// number of bytes read (0 on malformed input)
+func readOffsetVarint(b []byte) (int, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	c := b[0]
+	v := int(c & 0x7f)
+	i := 1
+	for c&0x80 != 0 {
+		if i >= len(b) {
+			return 0, 0
+		}
+		c = b[i]
+		i++
+		v = ((v + 1) << 7) | int(c&0x7f)
+	}
+	return v, i
+}
+
+// readFallback works out file states without the git binary: index entries
+// against HEAD for staged changes, against the working tree for
+// modifications, and files missing from the index are untracked
+func readFallback(repo repoDirs, root string) (map[string]domain.GitState, error) {
+	entries, err := readIndex(filepath.Join(repo.gitDir, "index"))
+	if err != nil {
+		return nil, err
+	}
+	objects := newObjectStore(filepath.Join(repo.commonDir, "objects"))
+	head, err := headTree(repo, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]domain.GitState)
+	tracked := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		tracked[e.path] = true
+		full := filepath.Join(repo.top, filepath.FromSlash(e.path))
+		switch {
+		case e.stage != 0:
+			states[full] = domain.GitConflicted
+		case states[full] == domain.GitConflicted:
+		case !e.skipWorktree && e.mode != modeGitlink && worktreeChanged(full, e):
+			states[full] = domain.GitModified
+		case head[e.path] != e.sha:
+			states[full] = domain.GitStaged
+		}
+	}
+
+	// ignore files above root still apply, as they do for git status
+	matcher := ignore.New(repo.top, nil)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable entries are simply skipped
+		}
+		if path == root {
+			return nil
+		}
+		if matcher.Match(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(repo.top, path)
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			// a tracked directory is a submodule with its own status
+			if tracked[filepath.ToSlash(rel)] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !tracked[filepath.ToSlash(rel)] {
+			states[path] = domain.GitUntracked
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// worktreeChanged compares a file with its index entry, trusting matching
+// size and mtime like git does and hashing the contents otherwise
+func worktreeChanged(path string, e indexEntry) bool {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return true
+	}
+	if uint32(fi.Size()) != e.size {
+		return true
+	}
+	mt := fi.ModTime()
+	if uint32(mt.Unix()) == e.mtimeSec && uint32(mt.Nanosecond()) == e.mtimeNsec {
+		return false
+	}
+	var content []byte
+	if e.mode == modeSymlink {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return true
+		}
+		content = []byte(filepath.ToSlash(target))
+	} else if content, err = os.ReadFile(path); err != nil {
+		return true
+	}
+	return blobHash(content) != e.sha
+}
+
+// blobHash is the object id git gives a file with these contents
+func blobHash(content []byte) [20]byte {
+	h := sha1.New()
+	h.Write([]byte("blob " + strconv.Itoa(len(content)) + "\x00"))
+	h.Write(content)
+	var sum [20]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
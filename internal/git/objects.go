@@ -0,0 +1,402 @@
+// internal/git/objects.go
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Object types as stored in packs
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var typeNames = map[string]int{"commit": objCommit, "tree": objTree, "blob": objBlob, "tag": objTag}
+
+// objectStore reads loose and packed objects of a repository
+type objectStore struct {
+	dir   string
+	packs []*pack // loaded on first use
+	ready bool
+}
+
+type pack struct {
+	path    string // the .pack file
+	shas    [][20]byte
+	offsets []int64
+}
+
+func newObjectStore(dir string) *objectStore {
+	return &objectStore{dir: dir}
+}
+
+// read returns an object's type and contents
+func (s *objectStore) read(sha [20]byte) (int, []byte, error) {
+	name := hex.EncodeToString(sha[:])
+	if b, err := os.ReadFile(filepath.Join(s.dir, name[:2], name[2:])); err == nil {
+		return parseLoose(b)
+	}
+	if err := s.loadPacks(); err != nil {
+		return 0, nil, err
+	}
+	for _, p := range s.packs {
+		if off, ok := p.find(sha); ok {
+			return s.readPacked(p, off)
+		}
+	}
+	return 0, nil, fmt.Errorf("object %s not found", name)
+}
+
+func parseLoose(b []byte) (int, []byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return 0, nil, fmt.Errorf("loose object: %w", err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("loose object: %w", err)
+	}
+	hdr, body, ok := bytes.Cut(data, []byte{0})
+	kind, _, _ := strings.Cut(string(hdr), " ")
+	t, known := typeNames[kind]
+	if !ok || !known {
+		return 0, nil, errors.New("loose object: bad header")
+	}
+	return t, body, nil
+}
+
+// loadPacks reads every version 2 pack index under objects/pack
+func (s *objectStore) loadPacks() error {
+	if s.ready {
+		return nil
+	}
+	s.ready = true
+	idxs, err := filepath.Glob(filepath.Join(s.dir, "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idx := range idxs {
+		p, err := readPackIndex(idx)
+		if err != nil {
+			return err
+		}
+		s.packs = append(s.packs, p)
+	}
+	return nil
+}
+
+func readPackIndex(path string) (*pack, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading pack index: %w", err)
+	}
+	if len(b) < 8+256*4 || !bytes.Equal(b[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(b[4:]) != 2 {
+		return nil, fmt.Errorf("%s: unsupported pack index", filepath.Base(path))
+	}
+	n := int(binary.BigEndian.Uint32(b[8+255*4:]))
+	shaStart := 8 + 256*4
+	offStart := shaStart + n*20 + n*4 // skip the CRC table
+	largeStart := offStart + n*4
+	if len(b) < largeStart {
+		return nil, fmt.Errorf("%s: truncated pack index", filepath.Base(path))
+	}
+	p := &pack{path: strings.TrimSuffix(path, ".idx") + ".pack", shas: make([][20]byte, n), offsets: make([]int64, n)}
+	for i := 0; i < n; i++ {
+		copy(p.shas[i][:], b[shaStart+i*20:])
+		off := binary.BigEndian.Uint32(b[offStart+i*4:])
+		if off&0x80000000 != 0 {
+			// large offsets live in a separate table of 8-byte entries
+			j := largeStart + int(off&0x7fffffff)*8
+			if j+8 > len(b) {
+				return nil, fmt.Errorf("%s: bad large offset", filepath.Base(path))
+			}
+			p.offsets[i] = int64(binary.BigEndian.Uint64(b[j:]))
+		} else {
+			p.offsets[i] = int64(off)
+		}
+	}
+	return p, nil
+}
+
+func (p *pack) find(sha [20]byte) (int64, bool) {
+	i := sort.Search(len(p.shas), func(i int) bool { return bytes.Compare(p.shas[i][:], sha[:]) >= 0 })
+	if i < len(p.shas) && p.shas[i] == sha {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+// readPacked reads the object at off, resolving deltas against their bases
+func (s *objectStore) readPacked(p *pack, off int64) (int, []byte, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading pack: %w", err)
+	}
+	defer f.Close()
+	return s.readPackedAt(f, p, off, 0)
+}
+
+// maxDeltaChain guards against corrupt packs that loop
+const maxDeltaChain = 1000
+
+func (s *objectStore) readPackedAt(f *os.File, p *pack, off int64, depth int) (int, []byte, error) {
+	if depth > maxDeltaChain {
+		return 0, nil, errors.New("pack: delta chain too long")
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, off, 1<<62))
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("pack: %w", err)
+	}
+	t := int(c>>4) & 7
+	size := int64(c & 0x0f)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, nil, fmt.Errorf("pack: %w", err)
+		}
+		size |= int64(c&0x7f) << shift
+	}
+
+	var baseType int
+	var base []byte
+	switch t {
+	case objOfsDelta:
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, fmt.Errorf("pack: %w", err)
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return 0, nil, fmt.Errorf("pack: %w", err)
+			}
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+		if baseType, base, err = s.readPackedAt(f, p, off-rel, depth+1); err != nil {
+			return 0, nil, err
+		}
+	case objRefDelta:
+		var sha [20]byte
+		if _, err := io.ReadFull(r, sha[:]); err != nil {
+			return 0, nil, fmt.Errorf("pack: %w", err)
+		}
+		if baseType, base, err = s.read(sha); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("pack: %w", err)
+	}
+	defer zr.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return 0, nil, fmt.Errorf("pack: %w", err)
+	}
+	if base == nil {
+		return t, data, nil
+	}
+	out, err := applyDelta(base, data)
+	return baseType, out, err
+}
+
+// applyDelta rebuilds an object from its base and a git delta
+func applyDelta(base, delta []byte) ([]byte, error) {
+	src, n := readSizeVarint(delta)
+	delta = delta[n:]
+	dst, n := readSizeVarint(delta)
+	delta = delta[n:]
+	if src != len(base) {
+		return nil, errors.New("delta: base size mismatch")
+	}
+	out := make([]byte, 0, dst)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch {
+		case op&0x80 != 0:
+			// copy: optional offset and size bytes selected by the low bits
+			var offset, size int
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, errors.New("delta: truncated copy")
+				}
+				if i < 4 {
+					offset |= int(delta[0]) << (8 * i)
+				} else {
+					size |= int(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, errors.New("delta: copy out of range")
+			}
+			out = append(out, base[offset:offset+size]...)
+		case op != 0:
+			if int(op) > len(delta) {
+				return nil, errors.New("delta: truncated insert")
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errors.New("delta: reserved opcode")
+		}
+	}
+	if len(out) != dst {
+		return nil, errors.New("delta: result size mismatch")
+	}
+	return out, nil
+}
+
+// readSizeVarint decodes the little-endian base-128 sizes in a delta header
+func readSizeVarint(b []byte) (int, int) {
+	v, shift := 0, 0
+	for i, c := range b {
+		v |= int(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, len(b)
+}
+
+// headTree returns the blob ids of HEAD's tree by path; an unborn branch has
+// an empty tree
+func headTree(repo repoDirs, objects *objectStore) (map[string][20]byte, error) {
+	sha, ok, err := resolveHead(repo)
+	if err != nil || !ok {
+		return map[string][20]byte{}, err
+	}
+	t, commit, err := objects.read(sha)
+	if err != nil {
+		return nil, err
+	}
+	if t != objCommit {
+		return nil, errors.New("HEAD is not a commit")
+	}
+	line, _, _ := bytes.Cut(commit, []byte{'\n'})
+	treeHex, ok := strings.CutPrefix(string(line), "tree ")
+	if !ok {
+		return nil, errors.New("HEAD commit has no tree")
+	}
+	tree, err := parseSHA(treeHex)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][20]byte)
+	return out, walkTree(objects, tree, "", out)
+}
+
+func walkTree(objects *objectStore, sha [20]byte, prefix string, out map[string][20]byte) error {
+	t, data, err := objects.read(sha)
+	if err != nil {
+		return err
+	}
+	if t != objTree {
+		return fmt.Errorf("%x is not a tree", sha)
+	}
+	// entries are "<octal mode> <name>\0<20-byte id>"
+	for len(data) > 0 {
+		sp := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if sp < 0 || nul < sp || nul+21 > len(data) {
+			return errors.New("tree: malformed entry")
+		}
+		mode, err := strconv.ParseUint(string(data[:sp]), 8, 32)
+		if err != nil {
+			return fmt.Errorf("tree: %w", err)
+		}
+		name := prefix + string(data[sp+1:nul])
+		var id [20]byte
+		copy(id[:], data[nul+1:nul+21])
+		data = data[nul+21:]
+		if mode == modeDir {
+			if err := walkTree(objects, id, name+"/", out); err != nil {
+				return err
+			}
+			continue
+		}
+		out[name] = id
+	}
+	return nil
+}
+
+// resolveHead follows HEAD to a commit id; ok is false on an unborn branch
+func resolveHead(repo repoDirs) ([20]byte, bool, error) {
+	b, err := os.ReadFile(filepath.Join(repo.gitDir, "HEAD"))
+	if err != nil {
+		return [20]byte{}, false, fmt.Errorf("reading HEAD: %w", err)
+	}
+	head := strings.TrimSpace(string(b))
+	for i := 0; i < 10; i++ {
+		ref, symbolic := strings.CutPrefix(head, "ref: ")
+		if !symbolic {
+			sha, err := parseSHA(head)
+			return sha, err == nil, err
+		}
+		next, found, err := readRef(repo, ref)
+		if err != nil || !found {
+			return [20]byte{}, false, err
+		}
+		head = next
+	}
+	return [20]byte{}, false, errors.New("HEAD: too many symbolic refs")
+}
+
+// readRef looks a ref up as a loose file, then in packed-refs
+func readRef(repo repoDirs, ref string) (string, bool, error) {
+	for _, dir := range []string{repo.gitDir, repo.commonDir} {
+		if b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(b)), true, nil
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(repo.commonDir, "packed-refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("reading packed-refs: %w", err)
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if sha, name, ok := strings.Cut(line, " "); ok && name == ref {
+			return sha, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func parseSHA(s string) ([20]byte, error) {
+	var sha [20]byte
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != 20 {
+		return sha, fmt.Errorf("bad object id %q", s)
+	}
+	copy(sha[:], b)
+	return sha, nil
+}
@@ -0,0 +1,288 @@
+// internal/ignore/ignore.go
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Files read from every directory, lowest precedence first. Like ripgrep,
+// .ignore wins over .gitignore in the same directory.
+var perDirFiles = []string{".gitignore", ".ignore"}
+
+// Matcher answers whether a path under root is ignored, following gitignore
+// semantics: extra patterns (e.g. Watch.Ignore) and .git/info/exclude apply
+// from the root, nested .gitignore/.ignore files apply to their own subtree,
+// deeper files override shallower ones and the last matching pattern wins.
+// A path inside an ignored directory is always ignored.
+//
+// A Matcher caches per-directory pattern files and results; it is not safe
+// for concurrent use.
+type Matcher struct {
+	root    string
+	global  []pattern
+	perDir  map[string][]pattern // rel dir -> patterns, loaded lazily
+	dirSeen map[string]bool      // rel dir -> ignored
+}
+
+type pattern struct {
+	base    string // rel dir the pattern was declared in ("" for root)
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// New builds a Matcher for root. Extra patterns use gitignore syntax and
+// behave as if listed first in the root .gitignore.
+func New(root string, extra []string) *Matcher {
+	m := &Matcher{root: filepath.Clean(root)}
+	// git never tracks its own metadata
+	m.global = append(m.global, parseLines("", []string{".git/"})...)
+	m.global = append(m.global, parseLines("", extra)...)
+	m.global = append(m.global, parseFile("", filepath.Join(m.root, ".git", "info", "exclude"))...)
+	m.Invalidate()
+	return m
+}
+
+// Invalidate drops cached ignore files and results, e.g. after a .gitignore
+// was edited.
+func (m *Matcher) Invalidate() {
+	m.perDir = make(map[string][]pattern)
+	m.dirSeen = make(map[string]bool)
+}
+
+// IsIgnoreFile reports whether name is one of the per-directory ignore files
+// whose changes should trigger Invalidate.
+func IsIgnoreFile(name string) bool {
+	for _, f := range perDirFiles {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether path (absolute or relative to root) is ignored.
+// Paths outside root and root itself are never ignored.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	rel, ok := m.rel(path)
+	if !ok {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.dirIgnored(strings.Join(parts[:i], "/")) {
+			return true
+		}
+	}
+	if isDir {
+		return m.dirIgnored(rel)
+	}
+	return m.matchOne(rel, false)
+}
+
+func (m *Matcher) rel(path string) (string, bool) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.root, path)
+	}
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func (m *Matcher) dirIgnored(rel string) bool {
+	if v, ok := m.dirSeen[rel]; ok {
+		return v
+	}
+	v := m.matchOne(rel, true)
+	m.dirSeen[rel] = v
+	return v
+}
+
+// matchOne evaluates every applicable pattern for rel in precedence order,
+// without looking at whether its ancestors are ignored.
+func (m *Matcher) matchOne(rel string, isDir bool) bool {
+	ignored := false
+	apply := func(ps []pattern) {
+		for _, p := range ps {
+			if p.dirOnly && !isDir {
+				continue
+			}
+			sub := rel
+			if p.base != "" {
+				sub = strings.TrimPrefix(rel, p.base+"/")
+			}
+			if p.re.MatchString(sub) {
+				ignored = !p.negate
+			}
+		}
+	}
+	apply(m.global)
+	apply(m.dirPatterns(""))
+	dir := ""
+	parts := strings.Split(rel, "/")
+	for _, part := range parts[:len(parts)-1] {
+		dir = joinRel(dir, part)
+		apply(m.dirPatterns(dir))
+	}
+	return ignored
+}
+
+func (m *Matcher) dirPatterns(rel string) []pattern {
+	if ps, ok := m.perDir[rel]; ok {
+		return ps
+	}
+	var ps []pattern
+	for _, name := range perDirFiles {
+		ps = append(ps, parseFile(rel, filepath.Join(m.root, filepath.FromSlash(rel), name))...)
+	}
+	m.perDir[rel] = ps
+	return ps
+}
+
+func joinRel(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
+
+func parseFile(base, path string) []pattern {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return parseLines(base, lines)
+}
+
+func parseLines(base string, lines []string) []pattern {
+	var out []pattern
+	for _, ln := range lines {
+		if p, ok := parsePattern(base, ln); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// parsePattern compiles a single gitignore line.
+func parsePattern(base, line string) (pattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimTrailingSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+	p := pattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern{}, false
+	}
+	// A slash anywhere but the end anchors the pattern to base
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	b.WriteString(globToRegexp(line))
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return pattern{}, false
+	}
+	p.re = re
+	return p, true
+}
+
+// globToRegexp converts gitignore glob syntax, including "**", to a regexp
+// body matched against slash-separated relative paths.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	rs := []rune(glob)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		switch c {
+		case '*':
+			if i+1 < len(rs) && rs[i+1] == '*' {
+				atStart := i == 0 || rs[i-1] == '/'
+				atEnd := i+2 == len(rs)
+				if atStart && atEnd {
+					b.WriteString(".*")
+					i++
+					continue
+				}
+				if atStart && rs[i+2] == '/' {
+					// "**/" matches zero or more directories
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+				// any other "**" is an ordinary "*"
+				i++
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			j := i + 1
+			if j < len(rs) && (rs[j] == '!' || rs[j] == '^') {
+				j++
+			}
+			if j < len(rs) && rs[j] == ']' {
+				j++
+			}
+			for j < len(rs) && rs[j] != ']' {
+				j++
+			}
+			if j >= len(rs) {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := string(rs[i+1 : j])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i = j
+		case '\\':
+			if i+1 < len(rs) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(rs[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// trimTrailingSpace drops unescaped trailing spaces; an escaped one is left
+// for globToRegexp to turn into a literal.
+func trimTrailingSpace(s string) string {
+	for strings.HasSuffix(s, " ") && !strings.HasSuffix(s, `\ `) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), "# comment\n*.log\n!keep.log\n/build\ndocs/**/*.pdf\nvendor/\ntmp/**\n")
+	writeFile(t, filepath.Join(root, "pkg", ".gitignore"), "*.gen.go\n!important.log\n")
+	writeFile(t, filepath.Join(root, "pkg", ".ignore"), "scratch/\n")
+	writeFile(t, filepath.Join(root, ".git", "info", "exclude"), "secret.txt\n")
+
+	m := New(root, []string{"node_modules/"})
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, false},
+		{"app.log", false, true},
+		{"keep.log", false, false},
+		{"sub/app.log", false, true},
+		{"build", true, true},
+		{"build/out.bin", false, true},
+		{"pkg/build", true, false},
+		{"docs/a.pdf", false, true},
+		{"docs/x/y/a.pdf", false, true},
+		{"docs/a.md", false, false},
+		{"vendor", true, true},
+		{"vendor", false, false},
+		{"a/vendor/x.go", false, true},
+		{"tmp", true, false},
+		{"tmp/x", false, true},
+		{"pkg/api.gen.go", false, true},
+		{"api.gen.go", false, false},
+		{"pkg/important.log", false, false},
+		{"pkg/scratch/x.go", false, true},
+		{"secret.txt", false, true},
+		{"web/node_modules/react/index.js", false, true},
+		{".git/config", false, true},
+	}
+	for _, tt := range tests {
+		if got := m.Match(filepath.Join(root, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+	if m.Match(root, true) {
+		t.Errorf("root must never be ignored")
+	}
+}
+
+func TestInvalidateReloadsFiles(t *testing.T) {
+	root := t.TempDir()
+	m := New(root, nil)
+	if m.Match(filepath.Join(root, "a.tmp"), false) {
+		t.Fatalf("nothing should be ignored yet")
+	}
+	writeFile(t, filepath.Join(root, ".gitignore"), "*.tmp\n")
+	m.Invalidate()
+	if !m.Match(filepath.Join(root, "a.tmp"), false) {
+		t.Fatalf("expected a.tmp ignored after reload")
+	}
+}
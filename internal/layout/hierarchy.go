@@ -0,0 +1,316 @@
+// internal/layout/hierarchy.go
+package layout
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/village-watch/internal/domain"
+)
+
+// SlotKind says what a slot holds
+type SlotKind int
+
+const (
+	SlotBuilding SlotKind = iota // A single file
+	SlotDistrict                 // A walled directory whose interior holds its children
+	SlotHamlet                   // Aggregate of nodes that did not fit, e.g. "+42"
+)
+
+var slotKindNames = []string{
+	SlotBuilding: "building",
+	SlotDistrict: "district",
+	SlotHamlet:   "hamlet",
+}
+
+func (k SlotKind) String() string {
+	if k >= 0 && int(k) < len(slotKindNames) {
+		return slotKindNames[k]
+	}
+	return fmt.Sprintf("slot(%d)", int(k))
+}
+
+const (
+	houseW, houseH = 4, 3 // footprint of a file building
+	gutter         = 1    // road between neighbouring areas
+	districtMinW   = 8    // walls, side padding and room for at least one hamlet
+	districtMinH   = 5
+	hamletMinW     = 3
+	// cells needed per item so that everything in a rect stays readable
+	itemArea = (houseW + gutter) * (houseH + gutter)
+)
+
+type rect struct{ x, y, w, h int }
+
+// item is one thing to place inside a district: a subdirectory, the yard of
+// its files, or a hamlet of overflow
+type item struct {
+	dir    *domain.FileNode
+	files  []*domain.FileNode
+	hidden int // hamlet: number of files aggregated
+	path   string
+	weight int
+}
+
+// Hierarchy lays out the whole tree below root: every directory becomes a
+// walled district whose interior recursively holds its children, files become
+// small houses in a yard, and whatever does not fit is folded into hamlets.
+// Gutters between areas become roads. Slots are returned parents first, so
+// painting them in order draws districts below their contents.
+func Hierarchy(root *domain.FileNode, cols, rows int) ([]Slot, []Slot) {
+	if root == nil || cols < 3 || rows < 3 {
+		return []Slot{}, []Slot{}
+	}
+	l := &hierarchyLayout{}
+	l.place(root.Path, l.itemsFor(root), rect{1, 1, cols - 2, rows - 2}, 0)
+	return l.slots, l.roads
+}
+
+type hierarchyLayout struct {
+	slots []Slot
+	roads []Slot
+}
+
+// itemsFor returns a directory's subdirectories followed by a single yard
+// item holding its files, each sorted by name
+func (l *hierarchyLayout) itemsFor(dir *domain.FileNode) []item {
+	var dirs []*domain.FileNode
+	var files []*domain.FileNode
+	for _, ch := range dir.Children {
+		if ch.IsDir {
+			dirs = append(dirs, ch)
+		} else {
+			files = append(files, ch)
+		}
+	}
+	byName := func(ns []*domain.FileNode) {
+		sort.Slice(ns, func(i, j int) bool { return strings.ToLower(ns[i].Name) < strings.ToLower(ns[j].Name) })
+	}
+	byName(dirs)
+	byName(files)
+
+	items := make([]item, 0, len(dirs)+1)
+	for _, d := range dirs {
+		items = append(items, item{dir: d, path: d.Path, weight: weight(d)})
+	}
+	if len(files) > 0 {
+		items = append(items, item{files: files, path: dir.Path, weight: len(files) * itemArea})
+	}
+	return items
+}
+
+// weight estimates the cells a node needs: a house per file plus walls and
+// gutters for every district
+func weight(n *domain.FileNode) int {
+	if !n.IsDir {
+		return itemArea
+	}
+	w := 2 * itemArea
+	for _, ch := range n.Children {
+		w += weight(ch)
+	}
+	return w
+}
+
+// EstimateArea returns the number of map cells Hierarchy needs to show every
+// node under root without folding any into hamlets
+func EstimateArea(root *domain.FileNode) int {
+	if root == nil {
+		return 0
+	}
+	return weight(root)
+}
+
+// countFiles is the number of houses a hamlet stands in for
+func countFiles(n *domain.FileNode) int {
+	if !n.IsDir {
+		return 1
+	}
+	c := 0
+	for _, ch := range n.Children {
+		c += countFiles(ch)
+	}
+	return c
+}
+
+func (it item) fileCount() int {
+	switch {
+	case it.dir != nil:
+		return countFiles(it.dir)
+	case it.files != nil:
+		return len(it.files)
+	default:
+		return it.hidden
+	}
+}
+
+// place splits r among items, the contents of the district at dir,
+// binary-partitioning along the longer side with a road gutter between the
+// halves
+func (l *hierarchyLayout) place(dir string, items []item, r rect, depth int) {
+	if len(items) == 0 || r.w <= 0 || r.h <= 0 {
+		return
+	}
+	items = fitItems(dir, items, r)
+	if len(items) == 1 {
+		l.placeItem(items[0], r, depth)
+		return
+	}
+
+	total := 0
+	for _, it := range items {
+		total += it.weight
+	}
+	// split point that balances the weight of both halves
+	k, acc, best := 1, 0, total
+	for i := 0; i < len(items)-1; i++ {
+		acc += items[i].weight
+		if d := abs(total - 2*acc); d < best {
+			best, k = d, i+1
+		}
+	}
+	leftWeight := 0
+	for _, it := range items[:k] {
+		leftWeight += it.weight
+	}
+
+	// terminal cells are about twice as tall as wide
+	vertical := r.w >= 2*r.h
+	span := r.h
+	if vertical {
+		span = r.w
+	}
+	avail := span - gutter
+	if avail < 2 {
+		l.placeItem(hamletOf(dir, items), r, depth)
+		return
+	}
+	cut := clamp((avail*leftWeight+total/2)/max(1, total), 1, avail-1)
+
+	if vertical {
+		l.place(dir, items[:k], rect{r.x, r.y, cut, r.h}, depth)
+		l.roads = append(l.roads, Slot{X: r.x + cut, Y: r.y, W: gutter, H: r.h, Path: "__road__"})
+		l.place(dir, items[k:], rect{r.x + cut + gutter, r.y, avail - cut, r.h}, depth)
+	} else {
+		l.place(dir, items[:k], rect{r.x, r.y, r.w, cut}, depth)
+		l.roads = append(l.roads, Slot{X: r.x, Y: r.y + cut, W: r.w, H: gutter, Path: "__road__"})
+		l.place(dir, items[k:], rect{r.x, r.y + cut + gutter, r.w, avail - cut}, depth)
+	}
+}
+
+// fitItems folds the lightest items into a hamlet of dir when r cannot give
+// every item a readable area; kept items stay in their original order
+func fitItems(dir string, items []item, r rect) []item {
+	capacity := (r.w + gutter) * (r.h + gutter) / itemArea
+	if len(items) <= max(1, capacity) {
+		return items
+	}
+	keep := max(0, capacity-1)
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return items[order[a]].weight > items[order[b]].weight })
+	kept := make(map[int]bool, keep)
+	for _, i := range order[:keep] {
+		kept[i] = true
+	}
+	var out, rest []item
+	for i, it := range items {
+		if kept[i] {
+			out = append(out, it)
+		} else {
+			rest = append(rest, it)
+		}
+	}
+	return append(out, hamletOf(dir, rest))
+}
+
+// hamletOf folds items into one hamlet that stands for the district at dir
+// holding them, as the hamlet ending a full yard does
+func hamletOf(dir string, items []item) item {
+	h := item{path: dir, weight: itemArea}
+	for _, it := range items {
+		h.hidden += it.fileCount()
+	}
+	return h
+}
+
+func (l *hierarchyLayout) placeItem(it item, r rect, depth int) {
+	switch {
+	case it.dir != nil:
+		if r.w < districtMinW || r.h < districtMinH {
+			l.hamlet(it.path, it.fileCount(), r, depth)
+			return
+		}
+		l.slots = append(l.slots, Slot{X: r.x, Y: r.y, W: r.w, H: r.h, Path: it.path, Kind: SlotDistrict, Depth: depth})
+		// wall plus one column of floor on each side keeps nested walls apart
+		l.place(it.path, l.itemsFor(it.dir), rect{r.x + 2, r.y + 1, r.w - 4, r.h - 2}, depth+1)
+	case it.files != nil:
+		l.yard(it.files, it.path, r, depth)
+	default:
+		l.hamlet(it.path, it.hidden, r, depth)
+	}
+}
+
+// yard packs houses in rows; if they do not all fit, the last lot becomes a
+// hamlet for the remainder
+func (l *hierarchyLayout) yard(files []*domain.FileNode, dir string, r rect, depth int) {
+	perRow := (r.w + gutter) / (houseW + gutter)
+	nRows := (r.h + gutter) / (houseH + gutter)
+	capacity := perRow * nRows
+	if capacity == 0 {
+		l.hamlet(dir, len(files), r, depth)
+		return
+	}
+	shown := len(files)
+	if shown > capacity {
+		shown = capacity - 1
+	}
+	// center the block of houses inside the yard
+	usedRows := (shown + perRow - 1) / perRow
+	if shown < len(files) {
+		usedRows = nRows
+	}
+	usedCols := min(perRow, max(1, len(files)))
+	offX := (r.w - (usedCols*(houseW+gutter) - gutter)) / 2
+	offY := (r.h - (usedRows*(houseH+gutter) - gutter)) / 2
+	lot := func(i int) (int, int) {
+		return r.x + offX + (i%perRow)*(houseW+gutter), r.y + offY + (i/perRow)*(houseH+gutter)
+	}
+	for i, f := range files[:shown] {
+		x, y := lot(i)
+		l.slots = append(l.slots, Slot{X: x, Y: y, W: houseW, H: houseH, Path: f.Path, Kind: SlotBuilding, Depth: depth})
+	}
+	if shown < len(files) {
+		x, y := lot(shown)
+		l.slots = append(l.slots, Slot{X: x, Y: y, W: houseW, H: houseH, Path: dir, Kind: SlotHamlet, Hidden: len(files) - shown, Depth: depth})
+	}
+}
+
+func (l *hierarchyLayout) hamlet(path string, hidden int, r rect, depth int) {
+	if r.w < hamletMinW || r.h < 1 {
+		return
+	}
+	w, h := min(r.w, houseW), min(r.h, houseH)
+	x, y := r.x+(r.w-w)/2, r.y+(r.h-h)/2
+	l.slots = append(l.slots, Slot{X: x, Y: y, W: w, H: h, Path: path, Kind: SlotHamlet, Hidden: hidden, Depth: depth})
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
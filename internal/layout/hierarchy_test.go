@@ -0,0 +1,133 @@
+package layout
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/domain"
+)
+
+// buildTree creates a root with dirs directories of files files each, plus
+// files loose files at the top level
+func buildTree(dirs, files int) *domain.FileNode {
+	root := &domain.FileNode{Path: "/r", Name: "r", IsDir: true}
+	addFiles := func(parent *domain.FileNode) {
+		for i := 0; i < files; i++ {
+			name := fmt.Sprintf("f%03d.go", i)
+			parent.Children = append(parent.Children, &domain.FileNode{Path: parent.Path + "/" + name, Name: name})
+		}
+	}
+	for d := 0; d < dirs; d++ {
+		name := fmt.Sprintf("d%03d", d)
+		dir := &domain.FileNode{Path: "/r/" + name, Name: name, IsDir: true}
+		addFiles(dir)
+		root.Children = append(root.Children, dir)
+	}
+	addFiles(root)
+	return root
+}
+
+func TestHierarchyAccountsForEveryFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		dirs, files int
+	}{
+		{"tiny", 1, 2},
+		{"medium", 6, 12},
+		{"large", 40, 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := buildTree(tt.dirs, tt.files)
+			slots, roads := Hierarchy(root, 128, 60)
+			want := tt.dirs*tt.files + tt.files
+
+			got := 0
+			for _, s := range slots {
+				if s.X < 0 || s.Y < 0 || s.X+s.W > 128 || s.Y+s.H > 60 {
+					t.Fatalf("slot out of bounds: %+v", s)
+				}
+				switch s.Kind {
+				case SlotBuilding:
+					got++
+				case SlotHamlet:
+					got += s.Hidden
+				}
+			}
+			if got != want {
+				t.Fatalf("accounted for %d files, want %d", got, want)
+			}
+			if tt.name != "tiny" && len(roads) == 0 {
+				t.Fatalf("expected roads between areas")
+			}
+		})
+	}
+}
+
+func TestHierarchyBuildingsDoNotOverlap(t *testing.T) {
+	slots, _ := Hierarchy(buildTree(8, 10), 128, 60)
+	var leaves []Slot
+	for _, s := range slots {
+		if s.Kind != SlotDistrict {
+			leaves = append(leaves, s)
+		}
+	}
+	for i := range leaves {
+		for j := i + 1; j < len(leaves); j++ {
+			a, b := leaves[i], leaves[j]
+			if a.X < b.X+b.W && b.X < a.X+a.W && a.Y < b.Y+b.H && b.Y < a.Y+a.H {
+				t.Fatalf("overlapping slots %+v and %+v", a, b)
+			}
+		}
+	}
+}
+
+func TestHierarchyScalesAndIsDeterministic(t *testing.T) {
+	root := buildTree(200, 50)
+	start := time.Now()
+	a, _ := Hierarchy(root, 128, 60)
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("layout of 10k files took %v", d)
+	}
+	b, _ := Hierarchy(root, 128, 60)
+	if len(a) != len(b) {
+		t.Fatalf("non-deterministic slot count %d vs %d", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("slot %d differs: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
+
+func TestHamletsOfEmptyDirectoriesHideNothing(t *testing.T) {
+	root := buildTree(200, 0)
+	slots, _ := Hierarchy(root, 40, 20)
+	hamlets := 0
+	for _, s := range slots {
+		if s.Kind == SlotHamlet {
+			hamlets++
+			if s.Hidden != 0 {
+				t.Fatalf("hamlet of empty directories claims %d files: %+v", s.Hidden, s)
+			}
+		}
+	}
+	if hamlets == 0 {
+		t.Fatalf("expected the directories to fold into hamlets")
+	}
+}
+
+func TestHamletsNameTheirDistrict(t *testing.T) {
+	// far more directories than the map holds, so siblings fold together
+	slots, _ := Hierarchy(buildTree(200, 1), 40, 20)
+	for _, s := range slots {
+		if s.Kind == SlotHamlet && s.Depth == 0 && s.Hidden > 1 {
+			if s.Path != "/r" {
+				t.Fatalf("hamlet of several directories names %s, want the district /r", s.Path)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected the directories to fold into a hamlet")
+}
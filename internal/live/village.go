@@ -0,0 +1,204 @@
+// internal/live/village.go
+package live
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/git"
+	"example.com/village-watch/internal/scan"
+	"example.com/village-watch/internal/tail"
+	"example.com/village-watch/internal/watch"
+)
+
+// Village is one watched directory shared by several viewers. It runs the
+// pipeline of the TUI once: watcher events, periodic rescans, git status and
+// lantern logs all update a single RepoState. It is safe for concurrent use;
+// viewers read the tree inside Frame.
+type Village struct {
+	root     string
+	cfg      config.Config
+	renderer *buildings.Renderer
+
+	mu          sync.Mutex
+	repo        *domain.RepoState
+	stater      *scan.Stater
+	gitStates   map[string]domain.GitState
+	logActivity map[string]domain.LogActivity
+
+	stop      func() error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Start scans root and keeps following it until Close
+func Start(root string, cfg config.Config, renderer *buildings.Renderer) (*Village, error) {
+	repo, err := scan.BuildTree(root, cfg)
+	if err != nil {
+		return nil, err
+	}
+	repo.Ruins = domain.DemolitionTime
+	var tailer *tail.Tailer
+	if cfg.Logs.Enabled {
+		rules, err := tail.CompileRules(cfg.Logs.Error, cfg.Logs.Warn)
+		if err != nil {
+			return nil, fmt.Errorf("logs: %w", err)
+		}
+		tailer = tail.New(rules)
+	}
+	out, stop, err := watch.Start(root, cfg)
+	if err != nil {
+		return nil, err
+	}
+	v := &Village{root: root, cfg: cfg, renderer: renderer, repo: repo, stater: scan.NewStater(root, cfg), stop: stop, done: make(chan struct{})}
+	go v.observe(out)
+	if cfg.Watch.ReconcileMS > 0 {
+		go v.every(time.Duration(cfg.Watch.ReconcileMS)*time.Millisecond, false, v.reconcile)
+	}
+	if cfg.Git.Enabled {
+		// git status walks the whole tree, so poll no faster than 4 times a second
+		go v.every(time.Duration(max(250, cfg.Git.RefreshMS))*time.Millisecond, true, v.readGit)
+	}
+	if tailer != nil {
+		go v.every(time.Duration(max(50, cfg.Logs.PollMS))*time.Millisecond, false, func() bool {
+			v.pollLogs(tailer)
+			return true
+		})
+	}
+	return v, nil
+}
+
+// Root is the directory the village shows
+func (v *Village) Root() string { return v.root }
+
+// Frame advances the animations to now and calls f with the tree, which f
+// must not keep or change
+func (v *Village) Frame(f func(repo *domain.RepoState)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.repo.PruneDemolished()
+	v.repo.UpdateStates()
+	f(v.repo)
+}
+
+// Read calls f with the tree as it is, which f must not keep or change
+func (v *Village) Read(f func(repo *domain.RepoState)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	f(v.repo)
+}
+
+// Rescan patches the tree toward a full rescan, which catches anything the
+// watcher missed
+func (v *Village) Rescan() {
+	scanned, err := scan.BuildTree(v.root, v.cfg)
+	if err != nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// the scan read every ignore file afresh; restat with the same rules
+	v.stater.Invalidate()
+	v.repo.Reconcile(v.repo.Diff(scanned), v.stater.Stat)
+	v.annotate()
+}
+
+// Close stops following the directory
+func (v *Village) Close() error {
+	var err error
+	v.closeOnce.Do(func() {
+		close(v.done)
+		err = v.stop()
+	})
+	return err
+}
+
+// observe applies watcher batches as they come
+func (v *Village) observe(out chan watch.EventOut) {
+	for {
+		select {
+		case <-v.done:
+			return
+		case batch, ok := <-out:
+			if !ok {
+				return
+			}
+			v.mu.Lock()
+			v.stater.Observe(batch.Events)
+			v.repo.Observe(batch.Events, v.stater.Stat)
+			v.annotate()
+			v.mu.Unlock()
+		}
+	}
+}
+
+// every calls f each interval until Close or until f returns false, first
+// right away when now is set
+func (v *Village) every(interval time.Duration, now bool, f func() bool) {
+	if now && !f() {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-v.done:
+			return
+		case <-t.C:
+			if !f() {
+				return
+			}
+		}
+	}
+}
+
+// reconcile rescans on every tick of the reconcile interval
+func (v *Village) reconcile() bool {
+	v.Rescan()
+	return true
+}
+
+// readGit applies git status; outside a repository it stops polling. On other
+// errors the last known states stay up.
+func (v *Village) readGit() bool {
+	states, err := git.Read(v.root)
+	if errors.Is(err, git.ErrNotRepo) {
+		return false
+	}
+	if err == nil {
+		v.mu.Lock()
+		v.gitStates = states
+		v.repo.ApplyGit(states)
+		v.mu.Unlock()
+	}
+	return true
+}
+
+// pollLogs reads what the lantern logs gained
+func (v *Village) pollLogs(t *tail.Tailer) {
+	var lanterns []string
+	v.mu.Lock()
+	for path, n := range v.repo.Index {
+		if !n.IsDir && v.renderer.Archetype(v.repo, n) == buildings.Lantern {
+			lanterns = append(lanterns, path)
+		}
+	}
+	v.mu.Unlock()
+	t.Sync(lanterns)
+	activity := t.Poll(time.Now())
+	v.mu.Lock()
+	v.logActivity = activity
+	v.repo.ApplyLogs(activity)
+	v.mu.Unlock()
+}
+
+// annotate reapplies the last git and log readings after the tree changed
+func (v *Village) annotate() {
+	v.repo.ApplyGit(v.gitStates)
+	v.repo.ApplyLogs(v.logActivity)
+}
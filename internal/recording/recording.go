@@ -0,0 +1,256 @@
+// internal/recording/recording.go
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/snapshot"
+)
+
+// Version is the session format written by Create; Read rejects any other
+const Version = 1
+
+// A session file is JSON Lines: a header with the tree the recording starts
+// from, then one line per watcher batch.
+type header struct {
+	Version int               `json:"version"`
+	Started time.Time         `json:"started"`
+	Tree    snapshot.Snapshot `json:"tree"`
+}
+
+// batchLine is a watcher batch, or the paths a rescan read again, and what
+// they looked like on disk when it was applied, so a replay needs neither the
+// directory nor its past state. Paths are relative to the tree's root and
+// slash-separated.
+type batchLine struct {
+	At     int64            `json:"at_ms"` // since Started
+	Events []event          `json:"events"`
+	Rescan []string         `json:"rescan,omitempty"` // restated without events, see domain.RepoState.Reconcile
+	Files  map[string]*file `json:"files"`            // null where the path was gone
+}
+
+type event struct {
+	Path string    `json:"path"`
+	Kind string    `json:"kind"` // created|modified|removed|renamed
+	When time.Time `json:"when"`
+}
+
+type file struct {
+	Name     string    `json:"name"`
+	Dir      bool      `json:"dir,omitempty"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Children []*file   `json:"children,omitempty"`
+}
+
+// Recorder writes a session as the watcher delivers it. It is not safe for
+// concurrent use.
+type Recorder struct {
+	f       *os.File
+	w       *bufio.Writer
+	root    string
+	started time.Time
+	files   map[string]*file // restats of the batch being applied
+}
+
+// Create starts a session file holding the tree of repo
+func Create(name string, repo *domain.RepoState) (*Recorder, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{f: f, w: bufio.NewWriter(f), root: repo.RootPath, started: time.Now(), files: map[string]*file{}}
+	if err := r.write(header{Version: Version, Started: r.started, Tree: snapshot.Take(repo, nil)}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Stat wraps stat, keeping what it returns for the next Record
+func (r *Recorder) Stat(stat domain.StatFunc) domain.StatFunc {
+	return func(path string) *domain.FileNode {
+		n := stat(path)
+		r.files[r.rel(path)] = fileOf(n)
+		return n
+	}
+}
+
+// Record writes a batch with the restats taken while it was applied
+func (r *Recorder) Record(events []domain.FsEvent) error {
+	line := batchLine{At: time.Since(r.started).Milliseconds(), Events: make([]event, len(events)), Files: r.files}
+	for i, e := range events {
+		line.Events[i] = event{Path: r.rel(e.Path), Kind: e.Kind.String(), When: e.When}
+	}
+	r.files = map[string]*file{}
+	return r.write(line)
+}
+
+// Rescan writes the paths a rescan, or the return from the editor, read
+// again, with the restats taken while they were applied
+func (r *Recorder) Rescan(paths []string) error {
+	line := batchLine{At: time.Since(r.started).Milliseconds(), Rescan: make([]string, len(paths)), Files: r.files}
+	for i, p := range paths {
+		line.Rescan[i] = r.rel(p)
+	}
+	r.files = map[string]*file{}
+	return r.write(line)
+}
+
+// Close ends the session file
+func (r *Recorder) Close() error {
+	return errors.Join(r.w.Flush(), r.f.Close())
+}
+
+// write adds a line, flushed at once so a crash loses at most the batch in
+// flight
+func (r *Recorder) write(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	r.w.Write(b)
+	r.w.WriteByte('\n')
+	return r.w.Flush()
+}
+
+func (r *Recorder) rel(path string) string {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func fileOf(n *domain.FileNode) *file {
+	if n == nil {
+		return nil
+	}
+	f := &file{Name: n.Name, Dir: n.IsDir, Size: n.Size, ModTime: n.ModTime}
+	for _, ch := range n.Children {
+		f.Children = append(f.Children, fileOf(ch))
+	}
+	return f
+}
+
+// Session is a recording read back
+type Session struct {
+	Started time.Time
+	Tree    snapshot.Snapshot // as it was when the recording started
+	Batches []Batch
+}
+
+// Batch is one recorded watcher batch or rescan
+type Batch struct {
+	At     time.Duration // since the recording started
+	Events []domain.FsEvent
+	Rescan []string // paths to reconcile rather than observe
+	files  map[string]*file
+	root   string
+}
+
+// Apply patches repo as the batch did when it was recorded
+func (b Batch) Apply(repo *domain.RepoState) {
+	if len(b.Events) > 0 {
+		repo.Observe(b.Events, b.Stat)
+	}
+	repo.Reconcile(b.Rescan, b.Stat)
+}
+
+// Stat answers restats from the recording. Paths it did not record read as
+// gone.
+func (b Batch) Stat(path string) *domain.FileNode {
+	rel, err := filepath.Rel(b.root, path)
+	if err != nil {
+		return nil
+	}
+	return nodeOf(b.files[filepath.ToSlash(rel)], path)
+}
+
+func nodeOf(f *file, path string) *domain.FileNode {
+	if f == nil {
+		return nil
+	}
+	n := &domain.FileNode{Path: path, Name: f.Name, IsDir: f.Dir, Size: f.Size, ModTime: f.ModTime}
+	if !n.IsDir {
+		n.Ext = domain.Ext(n.Name)
+	}
+	for _, ch := range f.Children {
+		if ch != nil {
+			n.Children = append(n.Children, nodeOf(ch, filepath.Join(path, ch.Name)))
+		}
+	}
+	return n
+}
+
+// Load reads the session in the named file
+func Load(name string) (*Session, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read decodes a session of this Version
+func Read(r io.Reader) (*Session, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20) // the header holds the whole tree
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+		return nil, errors.New("session: empty file")
+	}
+	var h header
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return nil, fmt.Errorf("session header: %w", err)
+	}
+	if h.Version != Version {
+		return nil, fmt.Errorf("session version %d, want %d", h.Version, Version)
+	}
+	s := &Session{Started: h.Started, Tree: h.Tree}
+	root := h.Tree.Root
+	for n := 2; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var line batchLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("session line %d: %w", n, err)
+		}
+		b := Batch{At: time.Duration(line.At) * time.Millisecond, files: line.Files, root: root}
+		for _, e := range line.Events {
+			kind, err := domain.ParseEventKind(e.Kind)
+			if err != nil {
+				return nil, fmt.Errorf("session line %d: %w", n, err)
+			}
+			b.Events = append(b.Events, domain.FsEvent{Path: filepath.Join(root, filepath.FromSlash(e.Path)), Kind: kind, When: e.When})
+		}
+		for _, p := range line.Rescan {
+			b.Rescan = append(b.Rescan, filepath.Join(root, filepath.FromSlash(p)))
+		}
+		s.Batches = append(s.Batches, b)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	return s, nil
+}
+
+// Duration is how long the recording ran until its last batch
+func (s *Session) Duration() time.Duration {
+	if len(s.Batches) == 0 {
+		return 0
+	}
+	return s.Batches[len(s.Batches)-1].At
+}
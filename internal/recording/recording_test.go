@@ -0,0 +1,104 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/scan"
+)
+
+func TestReplayMatchesRecordingAcrossAReconcile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Default()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	repo, err := scan.BuildTree(dir, cfg)
+	must(err)
+	repo.Ruins = domain.DemolitionTime // as the TUI keeps them
+	name := filepath.Join(t.TempDir(), "session.jsonl")
+	rec, err := Create(name, repo)
+	must(err)
+
+	// the watcher's batches, applied and recorded as the TUI does
+	observe := func(events ...domain.FsEvent) {
+		repo.Observe(events, rec.Stat(scan.Restat(dir, cfg)))
+		must(rec.Record(events))
+	}
+	now := time.Now()
+	must(os.Mkdir(filepath.Join(dir, "pkg"), 0o755))
+	must(os.WriteFile(filepath.Join(dir, "pkg", "util.go"), []byte("package pkg\n"), 0o644))
+	observe(domain.FsEvent{Path: filepath.Join(dir, "pkg"), Kind: domain.Create, When: now})
+	must(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	observe(domain.FsEvent{Path: filepath.Join(dir, "main.go"), Kind: domain.Write, When: now})
+	must(os.Remove(filepath.Join(dir, "pkg", "util.go")))
+	observe(domain.FsEvent{Path: filepath.Join(dir, "pkg", "util.go"), Kind: domain.Remove, When: now})
+	// changes the watcher missed, caught by a reconcile
+	must(os.WriteFile(filepath.Join(dir, "pkg", "late.go"), []byte("package pkg\n"), 0o644))
+	must(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+	scanned, err := scan.BuildTree(dir, cfg)
+	must(err)
+	paths := repo.Diff(scanned)
+	if len(paths) != 2 {
+		t.Fatalf("reconcile restates %v, want main.go and pkg/late.go", paths)
+	}
+	repo.Reconcile(paths, rec.Stat(scan.Restat(dir, cfg)))
+	must(rec.Rescan(paths))
+	must(rec.Close())
+	// the directory is not needed to replay
+	must(os.RemoveAll(dir))
+
+	session, err := Load(name)
+	must(err)
+	if len(session.Batches) != 4 {
+		t.Fatalf("batches = %d, want 4", len(session.Batches))
+	}
+	got, err := session.Tree.Repo()
+	must(err)
+	for _, b := range session.Batches {
+		b.Apply(got)
+	}
+	if got.Stats != repo.Stats {
+		t.Fatalf("stats = %+v, want %+v", got.Stats, repo.Stats)
+	}
+	if len(got.Index) != len(repo.Index) {
+		t.Fatalf("replayed %d nodes, want %d", len(got.Index), len(repo.Index))
+	}
+	for path, want := range repo.Index {
+		n := got.Index[path]
+		if n == nil || n.Size != want.Size || !n.ModTime.Equal(want.ModTime) || n.IsDir != want.IsDir || n.State != want.State {
+			t.Fatalf("%s = %+v, want %+v", path, n, want)
+		}
+	}
+	if h := got.History[filepath.Join(dir, "pkg", "util.go")]; len(h) != 1 || h[0].Kind != domain.Remove {
+		t.Fatalf("util.go history = %+v", h)
+	}
+}
+
+func TestReadRejects(t *testing.T) {
+	tests := []struct {
+		name, session, err string
+	}{
+		{"empty", "", "empty file"},
+		{"other version", `{"version": 2}`, "version 2"},
+		{"bad line", `{"version": 1, "tree": {"root": "/r"}}` + "\n{", "line 2"},
+		{"bad kind", `{"version": 1, "tree": {"root": "/r"}}` + "\n" + `{"events": [{"path": "a.go", "kind": "melted"}]}`, "melted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.session))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// internal/render/inspector.go
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+)
+
+// Inspector panel width limits, including the border
+const (
+	inspectorMinW = 24
+	inspectorMaxW = 44
+)
+
+// Inspection is what the inspector panel shows about a clicked slot
+type Inspection struct {
+	Path      string           // relative to the repository root
+	Node      *domain.FileNode // nil once the file is gone
+	Archetype buildings.Archetype
+	Design    string
+	Hidden    int // hamlets: number of buildings folded into it
+	History   []domain.FsEvent
+	Children  map[buildings.Archetype]int // directories: direct children by archetype
+}
+
+// InspectorWidth returns the panel width for a view cols wide, 0 if it does
+// not fit
+func InspectorWidth(cols int) int {
+	w := min(inspectorMaxW, cols/3)
+	if w < inspectorMinW {
+		return 0
+	}
+	return w
+}
+
+// Inspector renders the inspector panel line by line, at most rows lines
+// and width cells wide
+func Inspector(in Inspection, t Theme, width, rows int, unicode bool) []string {
+	if width < 4 || rows < 3 {
+		return nil
+	}
+	iw := width - 2
+	body := inspectorLines(in)
+	if len(body) > rows-2 {
+		body = body[:rows-2]
+	}
+	corners, horiz, vert := []string{"┌", "┐", "└", "┘"}, "─", "│"
+	if !unicode {
+		corners, horiz, vert = []string{"+", "+", "+", "+"}, "-", "|"
+	}
+	title := " inspector "
+	out := make([]string, 0, len(body)+2)
+	out = append(out, t.HUD.Render(corners[0]+title+strings.Repeat(horiz, max(0, iw-len(title)))+corners[1]))
+	for _, ln := range body {
+		ln = runewidth.FillRight(runewidth.Truncate(ln, iw, "…"), iw)
+		out = append(out, t.HUD.Render(vert+ln+vert))
+	}
+	out = append(out, t.HUD.Render(corners[2]+strings.Repeat(horiz, iw)+corners[3]))
+	return out
+}
+
+func inspectorLines(in Inspection) []string {
+	lines := []string{in.Path, ""}
+	if in.Node == nil {
+		return append(lines, "(removed)")
+	}
+	n := in.Node
+	if in.Hidden > 0 {
+		lines = append(lines, fmt.Sprintf("Hamlet: %d more here", in.Hidden))
+	}
+	if !n.IsDir {
+		lines = append(lines, "Size:      "+humanSize(n.Size))
+	}
+	if !n.ModTime.IsZero() {
+		lines = append(lines, "Modified:  "+n.ModTime.Format("2006-01-02 15:04:05"))
+	}
+	lines = append(lines, "Archetype: "+in.Archetype.String())
+	if n.Git != domain.GitClean {
+		lines = append(lines, "Git:       "+n.Git.String())
+	}
+	if n.Log != (domain.LogActivity{}) {
+		lines = append(lines, fmt.Sprintf("Log:       %.1f lines/s", n.Log.LinesPerSec))
+		if n.Log.Errors > 0 || n.Log.Warnings > 0 {
+			lines = append(lines, fmt.Sprintf("           %d errors, %d warnings", n.Log.Errors, n.Log.Warnings))
+		}
+	}
+	if in.Design != "" {
+		lines = append(lines, "Design:    "+in.Design)
+	}
+	if n.IsDir && len(in.Children) > 0 {
+		lines = append(lines, "", "Children:")
+		kinds := make([]buildings.Archetype, 0, len(in.Children))
+		for a := range in.Children {
+			kinds = append(kinds, a)
+		}
+		sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+		for _, a := range kinds {
+			lines = append(lines, fmt.Sprintf("  %-10s %d", a, in.Children[a]))
+		}
+	}
+	if len(in.History) > 0 {
+		lines = append(lines, "", "Recent events:")
+		// newest first
+		for i := len(in.History) - 1; i >= 0; i-- {
+			e := in.History[i]
+			lines = append(lines, fmt.Sprintf("  %s %s", e.When.Format("15:04:05"), e.Kind))
+		}
+	}
+	return lines
+}
+
+// humanSize formats a byte count with a binary unit
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+)
+
+func TestInspectorLines(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   Inspection
+		want []string
+	}{
+		{
+			name: "file",
+			in: Inspection{
+				Path:      "cmd/main.go",
+				Node:      &domain.FileNode{Size: 2048, ModTime: at},
+				Archetype: buildings.Cottage,
+				Design:    "Small Cottage",
+				History: []domain.FsEvent{
+					{Kind: domain.Create, When: at},
+					{Kind: domain.Write, When: at.Add(time.Minute)},
+				},
+			},
+			want: []string{
+				"cmd/main.go",
+				"",
+				"Size:      2.0 KiB",
+				"Modified:  2024-05-01 12:30:00",
+				"Archetype: cottage",
+				"Design:    Small Cottage",
+				"",
+				"Recent events:",
+				"  12:31:00 modified",
+				"  12:30:00 created",
+			},
+		},
+		{
+			name: "directory",
+			in: Inspection{
+				Path:      "docs",
+				Node:      &domain.FileNode{IsDir: true},
+				Archetype: buildings.District,
+				Children:  map[buildings.Archetype]int{buildings.Library: 3, buildings.Cottage: 1},
+			},
+			want: []string{"docs", "", "Archetype: district", "", "Children:", "  cottage    1", "  library    3"},
+		},
+		{
+			name: "removed",
+			in:   Inspection{Path: "gone.txt"},
+			want: []string{"gone.txt", "", "(removed)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := inspectorLines(tt.in)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestInspectorPanelWidth(t *testing.T) {
+	th := BundledThemes().Get("forest")
+	in := Inspection{Path: strings.Repeat("very/long/path/", 10), Node: &domain.FileNode{}}
+	for _, ln := range Inspector(in, th, 30, 20, true) {
+		if w := len([]rune(ln)); w != 30 {
+			t.Fatalf("line %q is %d cells wide, want 30", ln, w)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// internal/render/minimap.go
+package render
+
+import (
+	"strings"
+	"time"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/scene"
+)
+
+// Mini-map panel limits, in terminal cells including the border
+const (
+	miniMapMinW = 14
+	miniMapMaxW = 42
+)
+
+// blinkOn reports the current phase of blinking mini-map dots
+func blinkOn() bool {
+	return time.Now().UnixMilli()/400%2 == 0
+}
+
+// miniMapSize returns the inner size of a mini-map for a view of cols x rows
+// terminal cells, keeping the map's aspect ratio; 0, 0 when there is no room
+func miniMapSize(sc scene.Scene, cols, rows int) (int, int) {
+	if sc.MapW <= 0 || sc.MapH <= 0 {
+		return 0, 0
+	}
+	w := min(miniMapMaxW, max(miniMapMinW, cols/4))
+	if w > cols/2 || rows < 6 {
+		return 0, 0
+	}
+	iw := w - 2
+	ih := min(rows/2-2, max(2, iw*sc.MapH/sc.MapW))
+	return iw, ih
+}
+
+// MiniMap renders the bordered mini-map panel line by line: a downscaled
+// virtual map, the current viewport as a rectangle and, while blink is on,
+// dots where animations are active
+func MiniMap(sc scene.Scene, t Theme, cols, rows int, blink bool) []string {
+	iw, ih := miniMapSize(sc, cols, rows)
+	if iw == 0 {
+		return nil
+	}
+	cells, styles := miniMapCells(sc, iw, ih, blink)
+	corners, horiz, vert := []string{"┌", "┐", "└", "┘"}, "─", "│"
+	if !sc.Unicode {
+		corners, horiz, vert = []string{"+", "+", "+", "+"}, "-", "|"
+	}
+	title := " map "
+	top := corners[0] + title + strings.Repeat(horiz, max(0, iw-len(title))) + corners[1]
+	out := make([]string, 0, ih+2)
+	out = append(out, t.HUD.Render(top))
+	for y := range cells {
+		out = append(out, t.HUD.Render(vert)+RenderLine(cells[y], styles[y], t)+t.HUD.Render(vert))
+	}
+	out = append(out, t.HUD.Render(corners[2]+strings.Repeat(horiz, iw)+corners[3]))
+	return out
+}
+
+type miniGlyphs struct{ ground, road, building, anim, view rune }
+
+var (
+	miniUnicode = miniGlyphs{ground: ' ', road: '·', building: '█', anim: '●', view: '▫'}
+	miniASCII   = miniGlyphs{ground: ' ', road: '.', building: '#', anim: '*', view: 'o'}
+)
+
+// miniMapCells downscales the virtual map into iw x ih cells
+func miniMapCells(sc scene.Scene, iw, ih int, blink bool) ([]string, [][]buildings.CellStyle) {
+	g := miniUnicode
+	if !sc.Unicode {
+		g = miniASCII
+	}
+	styles := buildings.NewStyleGrid(iw, ih)
+	cells := make([]string, ih)
+
+	// viewport rectangle in mini-map cells
+	kx, ky := scene.ZoomScale(sc.Zoom)
+	vx0, vy0 := sc.ViewportX*iw/sc.MapW, sc.ViewportY*ih/sc.MapH
+	vx1 := min(iw-1, (sc.ViewportX+sc.W*kx-1)*iw/sc.MapW)
+	vy1 := min(ih-1, (sc.ViewportY+sc.H*ky-1)*ih/sc.MapH)
+
+	for y := 0; y < ih; y++ {
+		line := make([]rune, iw)
+		by, ey := y*sc.MapH/ih, (y+1)*sc.MapH/ih
+		for x := 0; x < iw; x++ {
+			bx, ex := x*sc.MapW/iw, (x+1)*sc.MapW/iw
+			st := scene.BlockStyle(sc.Styles, bx, by, max(1, ex-bx), max(1, ey-by))
+			onRect := (x == vx0 || x == vx1) && y >= vy0 && y <= vy1 || (y == vy0 || y == vy1) && x >= vx0 && x <= vx1
+			switch {
+			case st.State != domain.StateNormal && blink:
+				line[x] = g.anim
+			case onRect:
+				line[x] = g.view
+				st = buildings.CellStyle{Role: buildings.RoleLabel}
+			case st.State != domain.StateNormal:
+				// dot off: show the plain building underneath
+				line[x] = g.building
+				st.State = domain.StateNormal
+			case st.Role == buildings.RoleGround:
+				line[x] = g.ground
+			case st.Role == buildings.RoleRoad:
+				line[x] = g.road
+			default:
+				line[x] = g.building
+			}
+			styles[y][x] = st
+		}
+		cells[y] = string(line)
+	}
+	return cells, styles
+}
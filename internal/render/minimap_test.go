@@ -0,0 +1,72 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/scene"
+)
+
+func miniScene() scene.Scene {
+	// 40x20 map, view of the top-left 10x5 cells, a building far to the right
+	// that is being modified
+	styles := buildings.NewStyleGrid(40, 20)
+	for y := 12; y < 16; y++ {
+		for x := 32; x < 36; x++ {
+			styles[y][x] = buildings.CellStyle{Role: buildings.RoleWall, Archetype: buildings.Cottage, State: domain.StateModified}
+		}
+	}
+	return scene.Scene{W: 10, H: 5, MapW: 40, MapH: 20, Styles: styles, Unicode: true}
+}
+
+func TestMiniMapCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		blink bool
+		want  []string
+	}{
+		{"dot on", true, []string{
+			"▫▫▫       ",
+			"▫▫▫       ",
+			"          ",
+			"        ● ",
+			"          ",
+		}},
+		{"dot off", false, []string{
+			"▫▫▫       ",
+			"▫▫▫       ",
+			"          ",
+			"        █ ",
+			"          ",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells, styles := miniMapCells(miniScene(), 10, 5, tt.blink)
+			if strings.Join(cells, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got\n%s\nwant\n%s", strings.Join(cells, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if on := styles[3][8].State == domain.StateModified; on != tt.blink {
+				t.Fatalf("animation style shown = %v, want %v", on, tt.blink)
+			}
+		})
+	}
+}
+
+func TestMiniMapNeedsRoom(t *testing.T) {
+	th := BundledThemes().Get("forest")
+	sc := miniScene()
+	if got := MiniMap(sc, th, 20, 40, true); got != nil {
+		t.Fatalf("expected no panel in a 20-column view, got %d lines", len(got))
+	}
+	panel := MiniMap(sc, th, 120, 40, true)
+	iw, ih := miniMapSize(sc, 120, 40)
+	if len(panel) != ih+2 {
+		t.Fatalf("panel has %d lines, want %d", len(panel), ih+2)
+	}
+	if iw != 28 || ih != 14 {
+		t.Fatalf("inner size = %dx%d, want 28x14", iw, ih)
+	}
+}
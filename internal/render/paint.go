@@ -0,0 +1,122 @@
+// internal/render/paint.go
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+
+	lg "github.com/charmbracelet/lipgloss"
+
+	"example.com/village-watch/internal/buildings"
+)
+
+// Terminal colors assumed where a style leaves the foreground or background
+// to the terminal
+var (
+	DefaultFG = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	DefaultBG = color.RGBA{0x1c, 0x1c, 0x1c, 0xff}
+)
+
+// Paint is how a cell looks outside a terminal. A BG with zero alpha is the
+// terminal background.
+type Paint struct {
+	FG, BG color.RGBA
+	Bold   bool
+}
+
+// Paint resolves a cell's theme style to colors, the same style RenderLine
+// draws it with
+func (t Theme) Paint(c buildings.CellStyle) Paint {
+	return StylePaint(t.styleFor(keyFor(c)))
+}
+
+// StylePaint resolves a style to colors, applying reverse video and faint
+func StylePaint(st lg.Style) Paint {
+	fg, hasFG := RGB(st.GetForeground())
+	if !hasFG {
+		fg = DefaultFG
+	}
+	bg, _ := RGB(st.GetBackground())
+	if st.GetReverse() {
+		under := bg
+		if under.A == 0 {
+			under = DefaultBG
+		}
+		fg, bg = under, fg
+	}
+	if st.GetFaint() {
+		under := bg
+		if under.A == 0 {
+			under = DefaultBG
+		}
+		fg = mix(fg, under)
+	}
+	return Paint{FG: fg, BG: bg, Bold: st.GetBold()}
+}
+
+// RGB converts a theme color, an ANSI 256 index or #rgb/#rrggbb hex, to RGB;
+// ok is false for no color
+func RGB(c lg.TerminalColor) (rgb color.RGBA, ok bool) {
+	s, isColor := c.(lg.Color)
+	if !isColor || s == "" {
+		return color.RGBA{}, false
+	}
+	if s[0] == '#' {
+		var r, g, b uint8
+		switch len(s) {
+		case 4:
+			if _, err := fmt.Sscanf(string(s), "#%1x%1x%1x", &r, &g, &b); err != nil {
+				return color.RGBA{}, false
+			}
+			r, g, b = r*0x11, g*0x11, b*0x11
+		case 7:
+			if _, err := fmt.Sscanf(string(s), "#%02x%02x%02x", &r, &g, &b); err != nil {
+				return color.RGBA{}, false
+			}
+		default:
+			return color.RGBA{}, false
+		}
+		return color.RGBA{r, g, b, 0xff}, true
+	}
+	n, err := strconv.Atoi(string(s))
+	if err != nil || n < 0 || n > 255 {
+		return color.RGBA{}, false
+	}
+	return ansi256(n), true
+}
+
+// The 16 system colors as xterm draws them
+var ansi16 = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+// ansi256 is the xterm color of an ANSI 256 index: system colors, a 6x6x6
+// cube and a gray ramp
+func ansi256(n int) color.RGBA {
+	switch {
+	case n < 16:
+		return ansi16[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + 40*v)
+		}
+		return color.RGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 0xff}
+	default:
+		v := uint8(8 + 10*(n-232))
+		return color.RGBA{v, v, v, 0xff}
+	}
+}
+
+// mix is the color halfway between a and b
+func mix(a, b color.RGBA) color.RGBA {
+	avg := func(x, y uint8) uint8 { return uint8((int(x) + int(y)) / 2) }
+	return color.RGBA{avg(a.R, b.R), avg(a.G, b.G), avg(a.B, b.B), 0xff}
+}
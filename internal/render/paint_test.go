@@ -0,0 +1,48 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+
+	lg "github.com/charmbracelet/lipgloss"
+
+	"example.com/village-watch/internal/buildings"
+)
+
+func TestRGB(t *testing.T) {
+	cases := []struct {
+		in   lg.TerminalColor
+		want color.RGBA
+		ok   bool
+	}{
+		{lg.Color("#7fbf7f"), color.RGBA{0x7f, 0xbf, 0x7f, 0xff}, true},
+		{lg.Color("#fa0"), color.RGBA{0xff, 0xaa, 0x00, 0xff}, true},
+		{lg.Color("9"), color.RGBA{0xff, 0x00, 0x00, 0xff}, true},
+		{lg.Color("120"), color.RGBA{0x87, 0xff, 0x87, 0xff}, true},
+		{lg.Color("238"), color.RGBA{0x44, 0x44, 0x44, 0xff}, true},
+		{lg.Color("256"), color.RGBA{}, false},
+		{lg.NoColor{}, color.RGBA{}, false},
+	}
+	for _, c := range cases {
+		got, ok := RGB(c.in)
+		if got != c.want || ok != c.ok {
+			t.Errorf("RGB(%v) = %v, %v; want %v, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestPaintFollowsRenderLine(t *testing.T) {
+	th := ThemeByName("forest")
+	grass := th.Paint(buildings.CellStyle{})
+	if want, _ := RGB(lg.Color("120")); grass.FG != want || grass.BG.A != 0 {
+		t.Fatalf("grass = %+v", grass)
+	}
+	// reverse video swaps in the terminal background
+	cursor := StylePaint(lg.NewStyle().Reverse(true))
+	if cursor.FG != DefaultBG || cursor.BG != DefaultFG {
+		t.Fatalf("reversed = %+v", cursor)
+	}
+	if p := th.Paint(buildings.CellStyle{Role: buildings.RoleWall, Cursor: true}); !p.Bold || p.BG.A == 0 {
+		t.Fatalf("cursor = %+v, want the theme's bold background", p)
+	}
+}
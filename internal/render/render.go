@@ -2,27 +2,32 @@
 package render
 
 import (
+	"fmt"
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
 	"example.com/village-watch/internal/scene"
 	lg "github.com/charmbracelet/lipgloss"
 	"strings"
 )
 
 type Theme struct {
-	Ground lg.Style
-	HUD    lg.Style
+	Name       string
+	Ground     lg.Style // grass and anything without a more specific style
+	Road       lg.Style
+	Label      lg.Style
+	HUD        lg.Style
+	Highlight  lg.Style // search matches
+	Dimmed     lg.Style // buildings left out by the activity filter
+	Cursor     lg.Style // outline of the selected building
+	Archetypes map[buildings.Archetype]lg.Style
+	States     map[domain.FileState]lg.Style
+	Git        map[domain.GitState]lg.Style // roofs and flags of files git reports
+	Lamps      map[buildings.Lamp]lg.Style  // lanterns lit by their logs
 }
 
+// ThemeByName returns a bundled theme, falling back to forest
 func ThemeByName(name string) Theme {
-	switch name {
-	case "seaside":
-		return Theme{Ground: lg.NewStyle().Foreground(lg.Color("87")), HUD: lg.NewStyle().Foreground(lg.Color("81"))}
-	case "desert":
-		return Theme{Ground: lg.NewStyle().Foreground(lg.Color("179")), HUD: lg.NewStyle().Foreground(lg.Color("180"))}
-	case "contrast":
-		return Theme{Ground: lg.NewStyle().Foreground(lg.Color("15")).Background(lg.Color("0")), HUD: lg.NewStyle().Foreground(lg.Color("15")).Background(lg.Color("0"))}
-	default:
-		return Theme{Ground: lg.NewStyle().Foreground(lg.Color("120")), HUD: lg.NewStyle().Foreground(lg.Color("108"))}
-	}
+	return BundledThemes().Get(name)
 }
 
 func View(sc scene.Scene, t Theme, width, height int) string {
@@ -30,6 +35,12 @@ func View(sc scene.Scene, t Theme, width, height int) string {
 }
 
 func ViewWithStatus(sc scene.Scene, t Theme, width, height int, paused, filterActive bool, currentTheme string) string {
+	return ViewWithInspector(sc, t, width, height, paused, filterActive, currentTheme, nil)
+}
+
+// ViewWithInspector is ViewWithStatus plus, when in is not nil, the
+// inspector panel along the left edge
+func ViewWithInspector(sc scene.Scene, t Theme, width, height int, paused, filterActive bool, currentTheme string, in *Inspection) string {
 	// Draw canvas into available height-2 (reserve status bar and help line)
 	maxRows := height - 2
 	if maxRows < 1 {
@@ -39,21 +50,60 @@ func ViewWithStatus(sc scene.Scene, t Theme, width, height int, paused, filterAc
 	if len(lines) > maxRows {
 		lines = lines[:maxRows]
 	}
+	var left, right []string
+	leftWidth, rightWidth := 0, 0
+	if in != nil {
+		if leftWidth = InspectorWidth(sc.W); leftWidth > 0 {
+			left = Inspector(*in, t, leftWidth, len(lines), sc.Unicode)
+		}
+	}
+	if sc.MiniMapVisible {
+		right = MiniMap(sc, t, sc.W-leftWidth, len(lines), blinkOn())
+		if iw, _ := miniMapSize(sc, sc.W-leftWidth, len(lines)); iw > 0 {
+			rightWidth = iw + 2
+		}
+	}
 	b := strings.Builder{}
-	for _, ln := range lines {
-		b.WriteString(t.Ground.Render(ln))
+	for i, ln := range lines {
+		var styles []buildings.CellStyle
+		if i < len(sc.CanvasStyles) {
+			styles = sc.CanvasStyles[i]
+		}
+		var l, r string
+		lw, rw := 0, 0
+		if i < len(left) {
+			l, lw = left[i], leftWidth
+		}
+		if i < len(right) {
+			r, rw = right[i], rightWidth
+		}
+		b.WriteString(overlayPanels(ln, styles, l, lw, r, rw, t))
 		b.WriteByte('\n')
 	}
 	
 	statusLine := sc.Status
 	if paused { statusLine = "[PAUSED] " + statusLine }
-	if filterActive { statusLine = "[FILTERED] " + statusLine }
+	if filterActive {
+		if sc.Filter != "" {
+			statusLine = "[FILTER: " + sc.Filter + "] " + statusLine
+		} else {
+			statusLine = "[FILTERED] " + statusLine
+		}
+	}
 	if sc.LabelsVisible { statusLine = "[LABELS] " + statusLine }
+	if sc.Zoom > 0 {
+		kx, ky := scene.ZoomScale(sc.Zoom)
+		statusLine += fmt.Sprintf(" | Zoom: 1:%d", kx*ky)
+	}
 	statusLine += " | Theme: " + currentTheme
 	
 	b.WriteString(t.HUD.Render(statusLine))
 	b.WriteByte('\n')
-	b.WriteString(t.HUD.Render("(q) quit  (p) pause  (h) help  (f) filter  (l) labels  (t) theme  (r) refresh"))
+	if sc.Prompt != "" {
+		b.WriteString(t.HUD.Render(sc.Prompt))
+		return b.String()
+	}
+	b.WriteString(t.HUD.Render("(q) quit  (p) pause  (?) help  (f) filter  (L) labels  (m) map  (/) search  (tab) select  (e) edit  (t) theme  (r) refresh  (hjkl) pan  (+/-) zoom"))
 	return b.String()
 }
 
@@ -64,12 +114,31 @@ func ViewWithHelp(sc scene.Scene, t Theme, width, height int, paused, filterActi
 		"Controls:",
 		"  q / Ctrl+C  - Quit application",
 		"  p           - Pause/unpause updates",
-		"  h           - Toggle this help",
+		"  ?           - Toggle this help",
 		"  f           - Toggle activity filter",
-		"  t           - Cycle themes (forest/seaside/desert/contrast)",
-		"  r           - Force refresh filesystem",
+		"  F           - Edit filter: [dim|hide] recent:<min> | archetype:<name> | prefix:<path> | git:<state|dirty>",
+		"  L           - Toggle district labels",
+		"  m           - Toggle mini-map (viewport and activity)",
+		"  Click       - Inspect a building (Escape closes the panel)",
+		"  /           - Search paths; n / N jump to the next / previous match",
+		"  Tab / S-Tab - Move the selection cursor between buildings",
+		"  e / Enter   - Open the selected file in $VISUAL / $EDITOR",
+		"  Arrows/hjkl/WASD - Pan the map (or drag with the mouse)",
+		"  + / - / 0   - Zoom in / out / reset (or mouse wheel)",
+		"  t           - Cycle themes (bundled and user themes)",
+		"  r           - Force refresh filesystem (time-lapse, replay: restart)",
 		"  Escape      - Close overlays",
 		"",
+		"Time-lapse (--timelapse):",
+		"  Space       - Play / pause the replay",
+		"  [ / ]       - Slower / faster",
+		"  , / .       - Step back / forward one commit",
+		"",
+		"Replay (--replay):",
+		"  Space       - Play / pause the recording",
+		"  [ / ]       - Slower / faster",
+		"  , / .       - Step back / forward one batch of events",
+		"",
 		"Building Types:",
 		"  h/H/M - Cottages (Code files: Go, JS, Python, etc.)",
 		"  L     - Libraries (Documentation: MD, RST, TXT)",
@@ -97,3 +166,17 @@ func ViewWithHelp(sc scene.Scene, t Theme, width, height int, paused, filterActi
 	}
 	return b.String()
 }
+
+// overlayPanels draws a canvas line with already rendered panel lines over
+// its left and right ends, leftWidth and rightWidth cells wide
+func overlayPanels(line string, styles []buildings.CellStyle, left string, leftWidth int, right string, rightWidth int, t Theme) string {
+	runes := []rune(line)
+	from := min(leftWidth, len(runes))
+	to := max(from, len(runes)-rightWidth)
+	if len(styles) >= len(runes) {
+		styles = styles[from:to]
+	} else {
+		styles = nil
+	}
+	return left + RenderLine(string(runes[from:to]), styles, t) + right
+}
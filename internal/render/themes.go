@@ -0,0 +1,347 @@
+// internal/render/themes.go
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	lg "github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/themes"
+)
+
+// styleSpec is a color entry in a theme file
+type styleSpec struct {
+	FG   string `yaml:"fg"`
+	BG   string `yaml:"bg"`
+	Bold bool   `yaml:"bold"`
+}
+
+// themeFile is the on-disk format of themes/*.yaml
+type themeFile struct {
+	Name       string               `yaml:"name"`
+	Grass      styleSpec            `yaml:"grass"`
+	Roads      styleSpec            `yaml:"roads"`
+	Labels     styleSpec            `yaml:"labels"`
+	HUD        styleSpec            `yaml:"hud"`
+	Highlight  *styleSpec           `yaml:"highlight"`
+	Dimmed     *styleSpec           `yaml:"dimmed"`
+	Cursor     *styleSpec           `yaml:"cursor"`
+	Archetypes map[string]styleSpec `yaml:"archetypes"`
+	States     map[string]styleSpec `yaml:"states"`
+	Git        map[string]styleSpec `yaml:"git"`
+	Lanterns   map[string]styleSpec `yaml:"lanterns"`
+}
+
+var stateNames = map[string]domain.FileState{
+	"new":      domain.StateNew,
+	"modified": domain.StateModified,
+	"deleted":  domain.StateDeleted,
+}
+
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ThemeSet is the catalog of themes discovered at startup
+type ThemeSet struct {
+	names  []string
+	themes map[string]Theme
+}
+
+var bundled = sync.OnceValue(func() *ThemeSet {
+	set := &ThemeSet{themes: map[string]Theme{}}
+	// Bundled files are covered by tests; a broken one is simply skipped
+	_ = set.loadFS(themes.FS, ".")
+	return set
+})
+
+// BundledThemes returns the themes shipped in the themes/ directory
+func BundledThemes() *ThemeSet {
+	return bundled()
+}
+
+// DefaultThemeDir is where user themes live when themes_dir is not set
+func DefaultThemeDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "village-watch", "themes")
+}
+
+// LoadThemes returns the bundled themes plus every *.yaml in userDir; user
+// themes replace bundled ones of the same name. A missing userDir is fine.
+func LoadThemes(userDir string) (*ThemeSet, error) {
+	set := &ThemeSet{themes: map[string]Theme{}}
+	for _, name := range BundledThemes().names {
+		set.add(BundledThemes().themes[name])
+	}
+	if userDir == "" {
+		return set, nil
+	}
+	if _, err := os.Stat(userDir); errors.Is(err, os.ErrNotExist) {
+		return set, nil
+	}
+	if err := set.loadFS(os.DirFS(userDir), "."); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (s *ThemeSet) loadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("reading themes: %w", err)
+	}
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		b, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, e.Name())))
+		if err != nil {
+			return fmt.Errorf("reading theme: %w", err)
+		}
+		t, err := ParseTheme(b)
+		if err != nil {
+			return fmt.Errorf("theme %s: %w", e.Name(), err)
+		}
+		if t.Name == "" {
+			t.Name = strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		}
+		s.add(t)
+	}
+	return nil
+}
+
+func (s *ThemeSet) add(t Theme) {
+	if _, exists := s.themes[t.Name]; !exists {
+		s.names = append(s.names, t.Name)
+		sort.Strings(s.names)
+	}
+	s.themes[t.Name] = t
+}
+
+// Names lists the discovered themes in cycling order
+func (s *ThemeSet) Names() []string {
+	return append([]string{}, s.names...)
+}
+
+// Get returns the named theme, falling back to forest and then to a plain
+// built-in theme
+func (s *ThemeSet) Get(name string) Theme {
+	if t, ok := s.themes[name]; ok {
+		return t
+	}
+	if t, ok := s.themes["forest"]; ok {
+		return t
+	}
+	return Theme{Name: "plain", Ground: lg.NewStyle(), Road: lg.NewStyle(), Label: lg.NewStyle(), HUD: lg.NewStyle(), Highlight: lg.NewStyle().Reverse(true), Dimmed: lg.NewStyle().Faint(true), Cursor: lg.NewStyle().Reverse(true).Bold(true)}
+}
+
+// WithRenderer returns the themes with every style drawn by r, which knows
+// the color profile of a terminal other than our own
+func (s *ThemeSet) WithRenderer(r *lg.Renderer) *ThemeSet {
+	out := &ThemeSet{names: s.Names(), themes: make(map[string]Theme, len(s.themes))}
+	for name, t := range s.themes {
+		out.themes[name] = t.WithRenderer(r)
+	}
+	return out
+}
+
+// Next returns the theme after name in cycling order
+func (s *ThemeSet) Next(name string) string {
+	if len(s.names) == 0 {
+		return name
+	}
+	for i, n := range s.names {
+		if n == name {
+			return s.names[(i+1)%len(s.names)]
+		}
+	}
+	return s.names[0]
+}
+
+// ParseTheme decodes and validates a theme file. Archetypes, states and git
+// states without an entry use the grass style, lamps the lantern style.
+func ParseTheme(b []byte) (Theme, error) {
+	var f themeFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return Theme{}, err
+	}
+	t := Theme{
+		Name:       f.Name,
+		Archetypes: map[buildings.Archetype]lg.Style{},
+		States:     map[domain.FileState]lg.Style{},
+		Git:        map[domain.GitState]lg.Style{},
+		Lamps:      map[buildings.Lamp]lg.Style{},
+	}
+	var err error
+	if t.Ground, err = f.Grass.style("grass"); err != nil {
+		return Theme{}, err
+	}
+	if t.Road, err = f.Roads.style("roads"); err != nil {
+		return Theme{}, err
+	}
+	if t.Label, err = f.Labels.style("labels"); err != nil {
+		return Theme{}, err
+	}
+	if t.HUD, err = f.HUD.style("hud"); err != nil {
+		return Theme{}, err
+	}
+	// Search matches show in reverse video unless the theme says otherwise
+	t.Highlight = lg.NewStyle().Reverse(true)
+	if f.Highlight != nil {
+		if t.Highlight, err = f.Highlight.style("highlight"); err != nil {
+			return Theme{}, err
+		}
+	}
+	// Filtered-out buildings are drawn faint unless the theme picks a color
+	t.Dimmed = lg.NewStyle().Faint(true)
+	if f.Dimmed != nil {
+		if t.Dimmed, err = f.Dimmed.style("dimmed"); err != nil {
+			return Theme{}, err
+		}
+	}
+	t.Cursor = lg.NewStyle().Reverse(true).Bold(true)
+	if f.Cursor != nil {
+		if t.Cursor, err = f.Cursor.style("cursor"); err != nil {
+			return Theme{}, err
+		}
+	}
+	for name, spec := range f.Archetypes {
+		a, err := parseThemeArchetype(name)
+		if err != nil {
+			return Theme{}, err
+		}
+		if t.Archetypes[a], err = spec.style("archetypes." + name); err != nil {
+			return Theme{}, err
+		}
+	}
+	for name, spec := range f.States {
+		st, ok := stateNames[strings.ToLower(name)]
+		if !ok {
+			return Theme{}, fmt.Errorf("unknown animation state %q", name)
+		}
+		if t.States[st], err = spec.style("states." + name); err != nil {
+			return Theme{}, err
+		}
+	}
+	for name, spec := range f.Git {
+		g, err := domain.ParseGitState(name)
+		if err != nil || g == domain.GitClean {
+			return Theme{}, fmt.Errorf("unknown git state %q", name)
+		}
+		if t.Git[g], err = spec.style("git." + name); err != nil {
+			return Theme{}, err
+		}
+	}
+	for name, spec := range f.Lanterns {
+		l, ok := buildings.ParseLamp(name)
+		if !ok || l == buildings.LampDark {
+			return Theme{}, fmt.Errorf("unknown lantern light %q (want lit, bright, warn or error)", name)
+		}
+		if t.Lamps[l], err = spec.style("lanterns." + name); err != nil {
+			return Theme{}, err
+		}
+	}
+	return t, nil
+}
+
+// WithRenderer returns the theme with every style drawn by r
+func (t Theme) WithRenderer(r *lg.Renderer) Theme {
+	for _, st := range []*lg.Style{&t.Ground, &t.Road, &t.Label, &t.HUD, &t.Highlight, &t.Dimmed, &t.Cursor} {
+		*st = st.Renderer(r)
+	}
+	t.Archetypes = rebind(t.Archetypes, r)
+	t.States = rebind(t.States, r)
+	t.Git = rebind(t.Git, r)
+	t.Lamps = rebind(t.Lamps, r)
+	return t
+}
+
+func rebind[K comparable](styles map[K]lg.Style, r *lg.Renderer) map[K]lg.Style {
+	out := make(map[K]lg.Style, len(styles))
+	for k, st := range styles {
+		out[k] = st.Renderer(r)
+	}
+	return out
+}
+
+// ArchetypeStyle returns the style for an archetype, or the grass style
+func (t Theme) ArchetypeStyle(a buildings.Archetype) lg.Style {
+	if st, ok := t.Archetypes[a]; ok {
+		return st
+	}
+	return t.Ground
+}
+
+// StateStyle returns the style for an animation state, or the grass style
+func (t Theme) StateStyle(s domain.FileState) lg.Style {
+	if st, ok := t.States[s]; ok {
+		return st
+	}
+	return t.Ground
+}
+
+// GitStyle returns the style for a git state, or the grass style
+func (t Theme) GitStyle(g domain.GitState) lg.Style {
+	if st, ok := t.Git[g]; ok {
+		return st
+	}
+	return t.Ground
+}
+
+// LampStyle returns the style for a lit lantern, or the lantern style
+func (t Theme) LampStyle(l buildings.Lamp) lg.Style {
+	if st, ok := t.Lamps[l]; ok {
+		return st
+	}
+	return t.ArchetypeStyle(buildings.Lantern)
+}
+
+func parseThemeArchetype(name string) (buildings.Archetype, error) {
+	if strings.EqualFold(name, buildings.District.String()) {
+		return buildings.District, nil
+	}
+	return buildings.ParseArchetype(name)
+}
+
+func (s styleSpec) style(field string) (lg.Style, error) {
+	st := lg.NewStyle()
+	if s.FG != "" {
+		if !validColor(s.FG) {
+			return st, fmt.Errorf("%s: invalid fg color %q", field, s.FG)
+		}
+		st = st.Foreground(lg.Color(s.FG))
+	}
+	if s.BG != "" {
+		if !validColor(s.BG) {
+			return st, fmt.Errorf("%s: invalid bg color %q", field, s.BG)
+		}
+		st = st.Background(lg.Color(s.BG))
+	}
+	if s.Bold {
+		st = st.Bold(true)
+	}
+	return st, nil
+}
+
+// validColor accepts ANSI 256 indexes and #rgb/#rrggbb hex colors
+func validColor(c string) bool {
+	if hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
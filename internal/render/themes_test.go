@@ -0,0 +1,89 @@
+package render
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lg "github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+)
+
+func TestBundledThemesParse(t *testing.T) {
+	got := strings.Join(BundledThemes().Names(), ",")
+	if got != "contrast,desert,forest,seaside" {
+		t.Fatalf("bundled themes = %s", got)
+	}
+	for _, name := range BundledThemes().Names() {
+		th := BundledThemes().Get(name)
+		if len(th.Archetypes) != int(buildings.District)+1 {
+			t.Errorf("%s: %d archetype styles, want all", name, len(th.Archetypes))
+		}
+		if len(th.States) != 3 {
+			t.Errorf("%s: %d state styles, want 3", name, len(th.States))
+		}
+		if len(th.Git) != int(domain.GitConflicted) {
+			t.Errorf("%s: %d git styles, want 4", name, len(th.Git))
+		}
+		if len(th.Lamps) != int(buildings.LampError) {
+			t.Errorf("%s: %d lantern styles, want 4", name, len(th.Lamps))
+		}
+	}
+}
+
+func TestLoadThemesUserDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("midnight.yaml", "grass: { fg: \"#223344\" }\nstates:\n  new: { fg: \"226\" }\n")
+	write("forest.yml", "name: forest\ngrass: { fg: \"22\" }\n")
+
+	set, err := LoadThemes(dir)
+	if err != nil {
+		t.Fatalf("LoadThemes: %v", err)
+	}
+	if got := strings.Join(set.Names(), ","); got != "contrast,desert,forest,midnight,seaside" {
+		t.Fatalf("names = %s", got)
+	}
+	if set.Get("forest").Ground.GetForeground() != set.Get("forest").ArchetypeStyle(buildings.Cottage).GetForeground() {
+		t.Fatalf("user forest should replace bundled one and fall back to grass for archetypes")
+	}
+	if set.Next("midnight") != "seaside" || set.Next("seaside") != "contrast" {
+		t.Fatalf("unexpected cycling order")
+	}
+	if _, ok := set.Get("midnight").States[domain.StateNew]; !ok {
+		t.Fatalf("expected state style from user theme")
+	}
+
+	write("broken.yaml", "grass: { fg: \"chartreuse\" }\n")
+	if _, err := LoadThemes(dir); err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Fatalf("expected error naming broken.yaml, got %v", err)
+	}
+}
+
+func TestThemesWithRenderer(t *testing.T) {
+	color := lg.NewRenderer(io.Discard)
+	color.SetColorProfile(termenv.TrueColor)
+	plain := lg.NewRenderer(io.Discard)
+	plain.SetColorProfile(termenv.Ascii)
+
+	forest := BundledThemes().WithRenderer(color).Get("forest")
+	if got := forest.ArchetypeStyle(buildings.Cottage).Render("x"); !strings.Contains(got, "\x1b[") {
+		t.Fatalf("true color terminal got %q, want colors", got)
+	}
+	forest = forest.WithRenderer(plain)
+	for _, st := range []lg.Style{forest.Ground, forest.HUD, forest.GitStyle(domain.GitModified), forest.LampStyle(buildings.LampError)} {
+		if got := st.Render("x"); got != "x" {
+			t.Fatalf("ascii terminal got %q, want no escapes", got)
+		}
+	}
+}
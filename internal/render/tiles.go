@@ -0,0 +1,92 @@
+// internal/render/tiles.go
+package render
+
+import (
+	"strings"
+
+	lg "github.com/charmbracelet/lipgloss"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+)
+
+// styleKey identifies the theme style a cell resolves to, so that adjacent
+// cells with different roles but the same colors share one ANSI sequence
+type styleKey struct {
+	kind      uint8 // 0 ground, 1 road, 2 label, 3 archetype, 4 state, 5 highlight, 6 dimmed, 7 cursor, 8 git, 9 lamp
+	archetype buildings.Archetype
+	state     domain.FileState
+	git       domain.GitState
+	lamp      buildings.Lamp
+}
+
+func keyFor(c buildings.CellStyle) styleKey {
+	switch {
+	case c.Cursor:
+		return styleKey{kind: 7}
+	case c.Highlight:
+		return styleKey{kind: 5}
+	case c.Dim:
+		return styleKey{kind: 6}
+	case c.State != domain.StateNormal:
+		return styleKey{kind: 4, state: c.State}
+	case c.Lamp != buildings.LampDark:
+		return styleKey{kind: 9, lamp: c.Lamp}
+	case c.Git != domain.GitClean:
+		return styleKey{kind: 8, git: c.Git}
+	case c.Role == buildings.RoleRoad:
+		return styleKey{kind: 1}
+	case c.Role == buildings.RoleLabel:
+		return styleKey{kind: 2}
+	case c.Role == buildings.RoleGround:
+		return styleKey{}
+	default:
+		return styleKey{kind: 3, archetype: c.Archetype}
+	}
+}
+
+func (t Theme) styleFor(k styleKey) lg.Style {
+	switch k.kind {
+	case 1:
+		return t.Road
+	case 2:
+		return t.Label
+	case 3:
+		return t.ArchetypeStyle(k.archetype)
+	case 4:
+		return t.StateStyle(k.state)
+	case 5:
+		return t.Highlight
+	case 6:
+		return t.Dimmed
+	case 7:
+		return t.Cursor
+	case 8:
+		return t.GitStyle(k.git)
+	case 9:
+		return t.LampStyle(k.lamp)
+	default:
+		return t.Ground
+	}
+}
+
+// RenderLine colors one canvas line cell by cell, merging runs of cells that
+// resolve to the same style. Without styles the line is drawn as ground.
+func RenderLine(line string, styles []buildings.CellStyle, t Theme) string {
+	runes := []rune(line)
+	if len(styles) < len(runes) {
+		return t.Ground.Render(line)
+	}
+	var b strings.Builder
+	start := 0
+	for start < len(runes) {
+		k := keyFor(styles[start])
+		end := start + 1
+		for end < len(runes) && keyFor(styles[end]) == k {
+			end++
+		}
+		b.WriteString(t.styleFor(k).Render(string(runes[start:end])))
+		start = end
+	}
+	return b.String()
+}
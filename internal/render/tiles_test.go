@@ -0,0 +1,41 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	lg "github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+)
+
+func TestRenderLineMergesRuns(t *testing.T) {
+	lg.SetColorProfile(termenv.ANSI256)
+	defer lg.SetColorProfile(termenv.Ascii)
+
+	th := BundledThemes().Get("forest")
+	wall := buildings.CellStyle{Role: buildings.RoleWall, Archetype: buildings.Library}
+	door := buildings.CellStyle{Role: buildings.RoleDoor, Archetype: buildings.Library}
+	road := buildings.CellStyle{Role: buildings.RoleRoad}
+	smoke := buildings.CellStyle{Role: buildings.RoleWall, Archetype: buildings.Library, State: domain.StateModified}
+	line := "..##=#▫▫~"
+	styles := []buildings.CellStyle{{}, {}, wall, wall, door, wall, road, road, smoke}
+
+	out := RenderLine(line, styles, th)
+	// ground, library (wall+door merged), road, modified state
+	if got := strings.Count(out, "\x1b[0m"); got != 4 {
+		t.Fatalf("expected 4 styled runs, got %d in %q", got, out)
+	}
+	if !strings.Contains(out, "##=#") {
+		t.Fatalf("expected wall and door cells in one run: %q", out)
+	}
+}
+
+func TestRenderLineWithoutStyles(t *testing.T) {
+	th := BundledThemes().Get("forest")
+	if got := RenderLine("abc", nil, th); got != th.Ground.Render("abc") {
+		t.Fatalf("expected ground rendering, got %q", got)
+	}
+}
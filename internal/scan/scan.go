@@ -3,11 +3,13 @@ package scan
 
 import (
 	"io/fs"
+	"os"
 	"path/filepath"
 	"time"
 
 	"example.com/village-watch/internal/config"
 	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/ignore"
 )
 
 func BuildTree(root string, cfg config.Config) (*domain.RepoState, error) {
@@ -16,25 +18,71 @@ func BuildTree(root string, cfg config.Config) (*domain.RepoState, error) {
 	repo.Root = rootNode
 	repo.Index[root] = rootNode
 
-	ignore := func(p string) bool {
-		for _, g := range cfg.Watch.Ignore {
-			if match, _ := filepath.Match(g, filepath.Base(p)); match {
-				return true
-			}
-			if len(g) > 0 && g[len(g)-1] == '/' && stringsHasPathPrefix(p, filepath.Join(root, g[:len(g)-1])) {
-				return true
-			}
+	fill(rootNode, ignore.New(root, cfg.Watch.Ignore), repo.Index)
+	repo.LastRefresh = time.Now()
+	return repo, nil
+}
+
+// Restat returns a domain.StatFunc that re-reads single paths under root with
+// the same ignore rules as BuildTree. Directories come back with their subtree
+// already scanned.
+func Restat(root string, cfg config.Config) domain.StatFunc {
+	return NewStater(root, cfg).Stat
+}
+
+// Stater restats paths like Restat but keeps its ignore rules across
+// watcher batches; pass each batch to Observe so edited ignore files are
+// read again.
+type Stater struct {
+	matcher *ignore.Matcher
+}
+
+func NewStater(root string, cfg config.Config) *Stater {
+	return &Stater{matcher: ignore.New(root, cfg.Watch.Ignore)}
+}
+
+// Stat is a domain.StatFunc
+func (s *Stater) Stat(path string) *domain.FileNode {
+	info, err := os.Lstat(path)
+	if err != nil || s.matcher.Match(path, info.IsDir()) {
+		return nil
+	}
+	n := &domain.FileNode{
+		Path: path, Name: info.Name(), IsDir: info.IsDir(), ModTime: info.ModTime(), Size: info.Size(), Ext: domain.Ext(info.Name()),
+	}
+	if n.IsDir {
+		fill(n, s.matcher, map[string]*domain.FileNode{path: n})
+	}
+	return n
+}
+
+// Observe drops the cached ignore rules when events touch an ignore file
+func (s *Stater) Observe(events []domain.FsEvent) {
+	for _, e := range events {
+		if ignore.IsIgnoreFile(filepath.Base(e.Path)) {
+			s.Invalidate()
+			return
 		}
-		return false
 	}
-	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+}
+
+// Invalidate drops the cached ignore rules, e.g. ahead of a full rescan
+// that may have caught an ignore file the watcher missed
+func (s *Stater) Invalidate() {
+	s.matcher.Invalidate()
+}
+
+// fill walks the directory top and links every non-ignored entry below it
+// into index and its parent's Children.
+func fill(top *domain.FileNode, matcher *ignore.Matcher, index map[string]*domain.FileNode) {
+	filepath.WalkDir(top.Path, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
-		if path == root {
+		if path == top.Path {
 			return nil
 		}
-		if ignore(path) {
+		if matcher.Match(path, d.IsDir()) {
 			if d.IsDir() {
 				return fs.SkipDir
 			}
@@ -47,24 +95,16 @@ func BuildTree(root string, cfg config.Config) (*domain.RepoState, error) {
 		n := &domain.FileNode{
 			Path: path, Name: d.Name(), IsDir: d.IsDir(), ModTime: info.ModTime(), Size: info.Size(), Ext: domain.Ext(d.Name()),
 		}
-		repo.Upsert(n)
+		index[path] = n
 		parent := filepath.Dir(path)
-		if par, ok := repo.Index[parent]; ok {
+		if par, ok := index[parent]; ok {
 			par.Children = append(par.Children, n)
 		} else {
 			// ensure parent exists
 			par = &domain.FileNode{Path: parent, Name: filepath.Base(parent), IsDir: true}
-			repo.Upsert(par)
+			index[parent] = par
 			par.Children = append(par.Children, n)
 		}
 		return nil
 	})
-	repo.LastRefresh = time.Now()
-	return repo, nil
-}
-
-func stringsHasPathPrefix(p, prefix string) bool {
-	pp := filepath.Clean(p)
-	pf := filepath.Clean(prefix)
-	return len(pp) >= len(pf) && pp[:len(pf)] == pf
 }
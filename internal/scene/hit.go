@@ -0,0 +1,132 @@
+// internal/scene/hit.go
+package scene
+
+import (
+	"path/filepath"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/layout"
+)
+
+// SlotAt returns the innermost slot under a position in the view: a building
+// or hamlet before the district around it
+func (s *Scene) SlotAt(col, row int) (layout.Slot, bool) {
+	if s == nil || col < 0 || row < 0 || col >= s.W || row >= s.H {
+		return layout.Slot{}, false
+	}
+	x, y := s.MapCell(col, row)
+	kx, ky := ZoomScale(s.Zoom)
+	// Slots come parents first, so the last hit is the innermost
+	for i := len(s.Slots) - 1; i >= 0; i-- {
+		sl := s.Slots[i]
+		if x+kx > sl.X && x < sl.X+sl.W && y+ky > sl.Y && y < sl.Y+sl.H {
+			return sl, true
+		}
+	}
+	return layout.Slot{}, false
+}
+
+// MapCell converts a position in the view to virtual map coordinates
+func (s *Scene) MapCell(col, row int) (int, int) {
+	kx, ky := ZoomScale(s.Zoom)
+	return viewOrigin(s.W*kx, s.MapW, s.ViewportX) + col*kx, viewOrigin(s.H*ky, s.MapH, s.ViewportY) + row*ky
+}
+
+// Locate returns the slot showing path: its own building or district, or
+// else the hamlet or district of the nearest ancestor it was folded into
+func (s *Scene) Locate(path string) (layout.Slot, bool) {
+	if s == nil {
+		return layout.Slot{}, false
+	}
+	byPath := make(map[string][]layout.Slot)
+	for _, sl := range s.Slots {
+		byPath[sl.Path] = append(byPath[sl.Path], sl)
+	}
+	if sl, ok := pick(byPath[path], false); ok {
+		return sl, true
+	}
+	for p := filepath.Dir(path); ; p = filepath.Dir(p) {
+		if sl, ok := pick(byPath[p], true); ok {
+			return sl, true
+		}
+		if p == filepath.Dir(p) {
+			return layout.Slot{}, false
+		}
+	}
+}
+
+// pick chooses among the slots of one path, preferring a hamlet when the
+// path stands in for hidden files
+func pick(slots []layout.Slot, hidden bool) (layout.Slot, bool) {
+	if len(slots) == 0 {
+		return layout.Slot{}, false
+	}
+	for _, sl := range slots {
+		if (sl.Kind == layout.SlotHamlet) == hidden {
+			return sl, true
+		}
+	}
+	return slots[0], true
+}
+
+// Highlight marks the slots showing paths as search matches, replacing any
+// earlier matches, and redraws the canvas. Districts get only their outline
+// so their contents stay readable.
+func (s *Scene) Highlight(paths []string) {
+	if s == nil || s.Styles == nil {
+		return
+	}
+	s.markAll(func(c *buildings.CellStyle) { c.Highlight = false })
+	for _, p := range paths {
+		if sl, ok := s.Locate(p); ok {
+			s.mark(sl, sl.Kind == layout.SlotDistrict, func(c *buildings.CellStyle) { c.Highlight = true })
+		}
+	}
+	s.project()
+}
+
+// Select outlines the slot showing path with the selection cursor, moving it
+// from any earlier selection; an empty path clears it
+func (s *Scene) Select(path string) {
+	if s == nil || s.Styles == nil {
+		return
+	}
+	s.markAll(func(c *buildings.CellStyle) { c.Cursor = false })
+	if sl, ok := s.Locate(path); ok && path != "" {
+		s.mark(sl, true, func(c *buildings.CellStyle) { c.Cursor = true })
+	}
+	s.project()
+}
+
+// mark applies set to the cells of a slot, or only to its outline
+func (s *Scene) mark(sl layout.Slot, outline bool, set func(*buildings.CellStyle)) {
+	for y := max(0, sl.Y); y < min(len(s.Styles), sl.Y+sl.H); y++ {
+		for x := max(0, sl.X); x < min(len(s.Styles[y]), sl.X+sl.W); x++ {
+			edge := x == sl.X || x == sl.X+sl.W-1 || y == sl.Y || y == sl.Y+sl.H-1
+			if !outline || edge {
+				set(&s.Styles[y][x])
+			}
+		}
+	}
+}
+
+func (s *Scene) markAll(set func(*buildings.CellStyle)) {
+	for y := range s.Styles {
+		for x := range s.Styles[y] {
+			set(&s.Styles[y][x])
+		}
+	}
+}
+
+// Visible reports whether the whole slot is inside the view
+func (s *Scene) Visible(sl layout.Slot) bool {
+	x0, y0 := s.MapCell(0, 0)
+	x1, y1 := s.MapCell(s.W, s.H)
+	return sl.X >= x0 && sl.Y >= y0 && sl.X+sl.W <= x1 && sl.Y+sl.H <= y1
+}
+
+// Center moves the view so the slot is in its middle
+func (s *Scene) Center(sl layout.Slot) {
+	kx, ky := ZoomScale(s.Zoom)
+	s.SetViewport(sl.X+sl.W/2-s.W*kx/2, sl.Y+sl.H/2-s.H*ky/2)
+}
@@ -3,27 +3,77 @@ package scene
 
 import (
 	"fmt"
+	"time"
 
 	"example.com/village-watch/internal/buildings"
 	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/filter"
 	"example.com/village-watch/internal/layout"
 )
 
 type Tile struct{ Glyph string }
 
+// Fixed virtual map size used when no MapBounds are given; it also sets the
+// reference aspect ratio for dynamic sizing
 const (
 	VirtualMapWidth  = 128
 	VirtualMapHeight = 60
 )
 
+// MapBounds limits dynamic virtual map sizing; zero values mean no limit
+type MapBounds struct {
+	MinW, MinH int
+	MaxW, MaxH int
+}
+
 type Scene struct {
 	Canvas []string
 	W, H   int
 	Status string
 	VirtualMap [][]rune
-	ViewportX, ViewportY int
+	Styles     [][]buildings.CellStyle // per-cell style of VirtualMap
+	CanvasStyles [][]buildings.CellStyle // per-cell style of Canvas
+	ViewportX, ViewportY int // map cell at the top-left of the view
+	MapW, MapH int // virtual map dimensions
+	Slots []layout.Slot // building, district and hamlet slots, parents first
+	Zoom int // 0 is 1:1; see ZoomScale
 	buildingRenderer *buildings.Renderer
 	LabelsVisible bool
+	MiniMapVisible bool
+	Prompt string // replaces the key help line while the user types
+	Filter string // active filter expression, empty when off
+	Unicode bool // glyph set the map was drawn with
+}
+
+// Options tunes scene derivation beyond the viewport size
+type Options struct {
+	Unicode  bool
+	FPS      float64
+	Renderer *buildings.Renderer // nil uses the built-in designs and rules
+	Bounds   *MapBounds          // nil keeps the fixed VirtualMapWidth x VirtualMapHeight map
+	Viewport *Viewport           // nil centers the view on the map
+	Zoom     int
+	Filter   *filter.Filter // nil shows every building
+	Layout   *LayoutCache   // nil lays the tree out on every call
+}
+
+// LayoutCache keeps the last map size and layout so that frames of an
+// unchanged tree skip sizing and laying it out again. The zero value is
+// empty; it is not safe for concurrent use.
+type LayoutCache struct {
+	key          layoutKey
+	mapW, mapH   int
+	slots, roads []layout.Slot
+}
+
+// layoutKey is everything a layout depends on: the filter and animation
+// states only change how slots are drawn
+type layoutKey struct {
+	root       *domain.FileNode
+	generation uint64
+	cols, rows int
+	bounds     MapBounds
+	bounded    bool
 }
 
 func Derive(repo *domain.RepoState, cols, rows int, unicode bool) Scene {
@@ -39,27 +89,37 @@ func (s *Scene) DrawLabels(repo *domain.RepoState) {
 	if s == nil || s.buildingRenderer == nil || s.VirtualMap == nil || !s.LabelsVisible || repo == nil || repo.Root == nil {
 		return
 	}
-	slots, _ := layout.BSPWithRoads(repo.Root, VirtualMapWidth, VirtualMapHeight)
-	for _, slot := range slots {
+	for _, slot := range s.Slots {
 		node := repo.Index[slot.Path]
-		if node == nil || !node.IsDir { continue }
-		s.buildingRenderer.RenderLabel(s.VirtualMap, slot, node.Name, VirtualMapWidth, VirtualMapHeight)
+		if node == nil || slot.Kind != layout.SlotDistrict { continue }
+		s.buildingRenderer.RenderLabel(s.VirtualMap, s.Styles, slot, node.Name, s.MapW, s.MapH)
 	}
+	s.project()
 }
 
 func DeriveWithFPS(repo *domain.RepoState, cols, rows int, unicode bool, fps float64) Scene {
+	return DeriveWithOptions(repo, cols, rows, Options{Unicode: unicode, FPS: fps})
+}
+
+func DeriveWithOptions(repo *domain.RepoState, cols, rows int, opts Options) Scene {
 	if repo == nil || repo.Root == nil {
 		return Scene{Canvas: []string{"(empty)"}}
 	}
+	unicode, fps := opts.Unicode, opts.FPS
 	
-	// Create building renderer
-	buildingRenderer := buildings.NewRenderer()
+	// Use the configured building renderer when given
+	buildingRenderer := opts.Renderer
+	if buildingRenderer == nil {
+		buildingRenderer = buildings.NewRenderer()
+	}
 	
-	// Create virtual map (always 128x60)
-	virtualMap := make([][]rune, VirtualMapHeight)
+	// Size the virtual map for the tree and lay it out; styles default to ground
+	mapW, mapH, buildingSlots, roadSlots := layoutFor(repo, cols, rows, opts)
+	styles := buildings.NewStyleGrid(mapW, mapH)
+	virtualMap := make([][]rune, mapH)
 	for i := range virtualMap {
-		virtualMap[i] = make([]rune, VirtualMapWidth)
-		for j := 0; j < VirtualMapWidth; j++ {
+		virtualMap[i] = make([]rune, mapW)
+		for j := 0; j < mapW; j++ {
 			if unicode {
 				virtualMap[i][j] = '░' // Light grass/ground
 			} else {
@@ -68,25 +128,44 @@ func DeriveWithFPS(repo *domain.RepoState, cols, rows int, unicode bool, fps flo
 		}
 	}
 	
-	// Generate layout on virtual map dimensions
-	buildingSlots, roadSlots := layout.BSPWithRoads(repo.Root, VirtualMapWidth, VirtualMapHeight)
-	
 	// Render roads first (so buildings can overlap them)
 	for _, road := range roadSlots {
-		buildingRenderer.RenderRoad(virtualMap, road, VirtualMapWidth, VirtualMapHeight, unicode)
+		buildingRenderer.RenderRoad(virtualMap, styles, road, mapW, mapH, unicode)
 	}
 	
-	// Then render buildings using new modular system
+	// Then render districts and buildings, parents before their contents
+	var kept map[string]bool
+	if opts.Filter != nil {
+		kept = opts.Filter.Apply(repo, func(n *domain.FileNode) buildings.Archetype {
+			return buildingRenderer.Archetype(repo, n)
+		}, time.Now())
+	}
 	for _, s := range buildingSlots {
-		buildingRenderer.RenderBuilding(virtualMap, repo, s, VirtualMapWidth, VirtualMapHeight, unicode)
+		if kept != nil && !kept[s.Path] && opts.Filter.Hide {
+			continue
+		}
+		buildingRenderer.RenderBuilding(virtualMap, styles, repo, s, mapW, mapH, unicode)
+	}
+	// Dim what the filter leaves out once everything is drawn; a district
+	// that is left out holds nothing that is kept
+	if kept != nil && !opts.Filter.Hide {
+		for _, s := range buildingSlots {
+			if !kept[s.Path] {
+				dim(styles, s)
+			}
+		}
 	}
 	
-	// Create viewport of the virtual map
-	viewportX, viewportY := calculateViewport(cols, rows)
+	// Create viewport of the virtual map, centered unless the caller panned
+	zoom := clampZoom(opts.Zoom)
+	kx, ky := ZoomScale(zoom)
+	viewportX, viewportY := calculateViewport(cols*kx, rows*ky, mapW, mapH)
+	if opts.Viewport != nil {
+		viewportX, viewportY = clampViewport(opts.Viewport.X, opts.Viewport.Y, cols, rows, mapW, mapH, zoom)
+	}
 	// Optional labels overlay
 	// If labels are requested, draw them before extracting viewport by overlaying text near slots
 	// LabelsVisible flag is applied by UI after construction; we therefore provide a helper path below
-	canvas := extractViewport(virtualMap, cols, rows, viewportX, viewportY)
 	
 	// Count active animations
 	animCount := 0
@@ -114,45 +193,69 @@ func DeriveWithFPS(repo *domain.RepoState, cols, rows int, unicode bool, fps flo
 			repo.Stats.Deleted,
 			animCount)
 	}
+	if git := repo.Git.String(); git != "" {
+		status += " | Git: " + git
+	}
 	
 	sc := Scene{
-		Canvas: canvas,
 		W: cols, H: rows,
 		Status: status,
 		VirtualMap: virtualMap,
+		Styles: styles,
 		ViewportX: viewportX, ViewportY: viewportY,
+		MapW: mapW, MapH: mapH,
+		Slots: buildingSlots,
+		Zoom: zoom,
 		buildingRenderer: buildingRenderer,
+		Unicode: unicode,
+	}
+	if opts.Filter != nil {
+		sc.Filter = opts.Filter.String()
 	}
+	sc.project()
 	return sc
 }
 
-// addRoads draws paths between districts and major buildings (legacy function - kept for compatibility)
-func addRoads(grid [][]rune, slots []layout.Slot, cols, rows int, unicode bool) {
-	roadGlyph := '·'
-	if unicode {
-		roadGlyph = '▫'
+// layoutFor sizes the virtual map and lays the tree out on it, reusing
+// opts.Layout while nothing the layout depends on has changed
+func layoutFor(repo *domain.RepoState, cols, rows int, opts Options) (int, int, []layout.Slot, []layout.Slot) {
+	key := layoutKey{root: repo.Root, generation: repo.Generation, cols: cols, rows: rows}
+	if opts.Bounds != nil {
+		key.bounds, key.bounded = *opts.Bounds, true
 	}
-	
-	// Simple horizontal roads every few rows
-	for y := 3; y < rows; y += 4 {
-		for x := 0; x < cols; x++ {
-			if grid[y][x] == '░' || grid[y][x] == '.' {
-				grid[y][x] = roadGlyph
-			}
+	if c := opts.Layout; c != nil && c.key == key {
+		return c.mapW, c.mapH, c.slots, c.roads
+	}
+	mapW, mapH := VirtualMapWidth, VirtualMapHeight
+	if opts.Bounds != nil {
+		mapW, mapH = MapSize(repo.Root, cols, rows, *opts.Bounds)
+	}
+	slots, roads := layout.Hierarchy(repo.Root, mapW, mapH)
+	if opts.Layout != nil {
+		*opts.Layout = LayoutCache{key: key, mapW: mapW, mapH: mapH, slots: slots, roads: roads}
+	}
+	return mapW, mapH, slots, roads
+}
+
+// dim marks a slot's cells as filtered out
+func dim(styles [][]buildings.CellStyle, s layout.Slot) {
+	for y := max(0, s.Y); y < min(len(styles), s.Y+s.H); y++ {
+		for x := max(0, s.X); x < min(len(styles[y]), s.X+s.W); x++ {
+			styles[y][x].Dim = true
 		}
 	}
 }
 
 // calculateViewport determines where to position the viewport on the virtual map
-func calculateViewport(viewWidth, viewHeight int) (int, int) {
+func calculateViewport(viewWidth, viewHeight, mapW, mapH int) (int, int) {
 	// If viewport is larger than virtual map, center the virtual map
-	if viewWidth >= VirtualMapWidth && viewHeight >= VirtualMapHeight {
+	if viewWidth >= mapW && viewHeight >= mapH {
 		return 0, 0 // Show entire virtual map
 	}
 	
 	// If viewport is smaller, center it on the virtual map
-	viewportX := (VirtualMapWidth - viewWidth) / 2
-	viewportY := (VirtualMapHeight - viewHeight) / 2
+	viewportX := (mapW - viewWidth) / 2
+	viewportY := (mapH - viewHeight) / 2
 	
 	// Ensure viewport doesn't go negative
 	if viewportX < 0 {
@@ -165,52 +268,49 @@ func calculateViewport(viewWidth, viewHeight int) (int, int) {
 	return viewportX, viewportY
 }
 
-// extractViewport extracts a viewport from the virtual map
-func extractViewport(virtualMap [][]rune, viewWidth, viewHeight, viewportX, viewportY int) []string {
+// extractViewport extracts a viewport from the virtual map and its styles;
+// padding cells get the zero (ground) style. An axis on which the view is
+// larger than the map shows the map centered with padding.
+func extractViewport(virtualMap [][]rune, styles [][]buildings.CellStyle, viewWidth, viewHeight, viewportX, viewportY int) ([]string, [][]buildings.CellStyle) {
+	mapH := len(virtualMap)
+	mapW := 0
+	if mapH > 0 {
+		mapW = len(virtualMap[0])
+	}
 	canvas := make([]string, viewHeight)
+	canvasStyles := buildings.NewStyleGrid(viewWidth, viewHeight)
+	originX := viewOrigin(viewWidth, mapW, viewportX)
+	originY := viewOrigin(viewHeight, mapH, viewportY)
 	
 	for y := 0; y < viewHeight; y++ {
 		line := make([]rune, viewWidth)
 		for x := 0; x < viewWidth; x++ {
-			mapX := viewportX + x
-			mapY := viewportY + y
-			
-			// If viewport is larger than virtual map, show virtual map centered with padding
-			if viewWidth > VirtualMapWidth || viewHeight > VirtualMapHeight {
-				// Calculate centering offsets
-				offsetX := (viewWidth - VirtualMapWidth) / 2
-				offsetY := (viewHeight - VirtualMapHeight) / 2
-				
-				if x >= offsetX && x < offsetX+VirtualMapWidth && 
-				   y >= offsetY && y < offsetY+VirtualMapHeight {
-					// Inside virtual map bounds
-					virtualX := x - offsetX
-					virtualY := y - offsetY
-					if virtualX >= 0 && virtualX < VirtualMapWidth && 
-					   virtualY >= 0 && virtualY < VirtualMapHeight {
-						line[x] = virtualMap[virtualY][virtualX]
-					} else {
-						line[x] = ' ' // Padding
-					}
-				} else {
-					line[x] = ' ' // Padding around virtual map
+			mapX := originX + x
+			mapY := originY + y
+			if mapX >= 0 && mapX < mapW && mapY >= 0 && mapY < mapH {
+				line[x] = virtualMap[mapY][mapX]
+				if styles != nil {
+					canvasStyles[y][x] = styles[mapY][mapX]
 				}
 			} else {
-				// Normal viewport (smaller than virtual map)
-				if mapX >= 0 && mapX < VirtualMapWidth && 
-				   mapY >= 0 && mapY < VirtualMapHeight {
-					line[x] = virtualMap[mapY][mapX]
-				} else {
-					line[x] = ' ' // Outside virtual map bounds
-				}
+				line[x] = ' ' // Padding outside virtual map bounds
 			}
 		}
 		canvas[y] = string(line)
 	}
 	
-	return canvas
+	return canvas, canvasStyles
+}
+
+// viewOrigin is the map coordinate shown at view position 0 along one axis
+func viewOrigin(view, mapSize, viewport int) int {
+	if view > mapSize {
+		return -(view - mapSize) / 2
+	}
+	return viewport
 }
 
 func ExtractViewportForUI(virtualMap [][]rune, viewWidth, viewHeight, viewportX, viewportY int) []string {
-	return extractViewport(virtualMap, viewWidth, viewHeight, viewportX, viewportY)
+	canvas, _ := extractViewport(virtualMap, nil, viewWidth, viewHeight, viewportX, viewportY)
+	return canvas
 }
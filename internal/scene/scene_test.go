@@ -1,9 +1,13 @@
 package scene
 
 import (
+	"strings"
 	"testing"
 
+	"example.com/village-watch/internal/buildings"
 	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/filter"
+	"example.com/village-watch/internal/layout"
 )
 
 func mockRepoDir(name string, x string) *domain.RepoState {
@@ -53,3 +57,210 @@ func TestDeriveWithFPSConsistentCanvasSize(t *testing.T) {
 		}
 	}
 }
+
+func TestSlotAt(t *testing.T) {
+	repo := mockRepoDir("pkg", "")
+	f := &domain.FileNode{Path: "/pkg/main.go", Name: "main.go", Ext: ".go"}
+	repo.Upsert(f)
+	repo.Index["/pkg"].Children = append(repo.Index["/pkg"].Children, f)
+	sc := Derive(repo, VirtualMapWidth, VirtualMapHeight, true)
+
+	var house layout.Slot
+	for _, s := range sc.Slots {
+		if s.Path == f.Path {
+			house = s
+		}
+	}
+	if house.W == 0 {
+		t.Fatalf("no slot for %s in %+v", f.Path, sc.Slots)
+	}
+	tests := []struct {
+		name     string
+		x, y     int
+		wantPath string
+		wantOK   bool
+	}{
+		{"house", house.X + 1, house.Y + 1, "/pkg/main.go", true},
+		{"district floor", house.X - 1, house.Y, "/pkg", true},
+		{"ground", 0, 0, "", false},
+		{"outside view", -1, 3, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := sc.SlotAt(tt.x, tt.y)
+			if ok != tt.wantOK || s.Path != tt.wantPath {
+				t.Fatalf("SlotAt(%d,%d) = %q, %v; want %q, %v", tt.x, tt.y, s.Path, ok, tt.wantPath, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLocateAndHighlight(t *testing.T) {
+	// More files than the fixed map holds, so the last ones fold into a hamlet
+	repo := mockRepoFiles(400)
+	sc := Derive(repo, 40, 20, true)
+
+	shown, ok := sc.Locate("/f00000.go")
+	if !ok || shown.Kind != layout.SlotBuilding || shown.Path != "/f00000.go" {
+		t.Fatalf("Locate(first file) = %+v, %v", shown, ok)
+	}
+	hidden, ok := sc.Locate("/f00399.go")
+	if !ok || hidden.Kind != layout.SlotHamlet {
+		t.Fatalf("Locate(folded file) = %+v, %v; want its hamlet", hidden, ok)
+	}
+
+	sc.Highlight([]string{"/f00000.go"})
+	if !sc.Styles[shown.Y][shown.X].Highlight {
+		t.Fatalf("matched building not highlighted")
+	}
+	sc.Highlight(nil)
+	if sc.Styles[shown.Y][shown.X].Highlight {
+		t.Fatalf("highlight not cleared")
+	}
+
+	// The hamlet sits near the map edge, so centering is clamped but must
+	// still bring it into view
+	sc.Center(hidden)
+	if hidden.X < sc.ViewportX || hidden.X+hidden.W > sc.ViewportX+40 || hidden.Y < sc.ViewportY || hidden.Y+hidden.H > sc.ViewportY+20 {
+		t.Fatalf("hamlet %+v not inside view at %d,%d", hidden, sc.ViewportX, sc.ViewportY)
+	}
+}
+
+func TestDeriveAppliesFilter(t *testing.T) {
+	repo := mockRepoFiles(2)
+	keep := filter.Filter{Kind: filter.Prefix, Prefix: "f00000"}
+	tests := []struct {
+		name      string
+		hide      bool
+		wantDim   bool
+		wantDrawn bool
+	}{
+		{"dim", false, true, true},
+		{"hide", true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := keep
+			f.Hide = tt.hide
+			sc := DeriveWithOptions(repo, 40, 20, Options{Unicode: true, Filter: &f})
+			other, ok := sc.Locate("/f00001.go")
+			if !ok {
+				t.Fatal("no slot for the filtered-out file")
+			}
+			st := sc.Styles[other.Y][other.X]
+			if st.Dim != tt.wantDim || (st.Role != buildings.RoleGround) != tt.wantDrawn {
+				t.Fatalf("filtered-out building: %+v", st)
+			}
+			kept, _ := sc.Locate("/f00000.go")
+			if st := sc.Styles[kept.Y][kept.X]; st.Dim || st.Role == buildings.RoleGround {
+				t.Fatalf("kept building: %+v", st)
+			}
+		})
+	}
+}
+
+func TestSelectOutlinesOneBuilding(t *testing.T) {
+	repo := mockRepoFiles(3)
+	sc := Derive(repo, 40, 20, true)
+	a, _ := sc.Locate("/f00000.go")
+	b, _ := sc.Locate("/f00001.go")
+
+	sc.Select(a.Path)
+	sc.Select(b.Path)
+	if sc.Styles[a.Y][a.X].Cursor {
+		t.Fatalf("cursor left behind on the previous selection")
+	}
+	if !sc.Styles[b.Y][b.X].Cursor || sc.Styles[b.Y+1][b.X+1].Cursor {
+		t.Fatalf("expected only the outline of %s to carry the cursor", b.Path)
+	}
+	sc.Select("")
+	if sc.Styles[b.Y][b.X].Cursor {
+		t.Fatalf("empty path should clear the cursor")
+	}
+}
+
+func TestDeriveDrawsGitBanners(t *testing.T) {
+	repo := mockRepoFiles(2)
+	repo.ApplyGit(map[string]domain.GitState{"/f00001.go": domain.GitModified})
+	sc := Derive(repo, 40, 20, false)
+	clean, _ := sc.Locate("/f00000.go")
+	dirty, _ := sc.Locate("/f00001.go")
+
+	if got := sc.VirtualMap[dirty.Y][dirty.X+dirty.W-1]; got != '*' {
+		t.Fatalf("flag = %q, want '*'", got)
+	}
+	if sc.Styles[dirty.Y][dirty.X].Git != domain.GitModified || sc.Styles[dirty.Y+1][dirty.X].Git != domain.GitClean {
+		t.Fatalf("expected only the roof row of %s to carry the git state", dirty.Path)
+	}
+	if sc.Styles[clean.Y][clean.X].Git != domain.GitClean {
+		t.Fatalf("clean file should have no banner")
+	}
+	if !strings.Contains(sc.Status, "Git: 1 modified") {
+		t.Fatalf("status %q lacks the git summary", sc.Status)
+	}
+}
+
+func TestDeriveLightsLanterns(t *testing.T) {
+	repo := mockRepoFiles(1)
+	logNode := &domain.FileNode{Path: "/app.log", Name: "app.log", Ext: ".log", Size: 4096}
+	repo.Upsert(logNode)
+	repo.Root.Children = append(repo.Root.Children, logNode)
+	repo.ApplyLogs(map[string]domain.LogActivity{"/app.log": {LinesPerSec: 12, Errors: 2}})
+
+	sc := Derive(repo, 40, 20, false)
+	lantern, _ := sc.Locate("/app.log")
+	other, _ := sc.Locate("/f00000.go")
+	if got := sc.Styles[lantern.Y+1][lantern.X+1].Lamp; got != buildings.LampError {
+		t.Fatalf("lantern interior lamp = %v, want error", got)
+	}
+	glow := false
+	for dx := -1; dx <= lantern.W; dx++ {
+		for _, y := range []int{lantern.Y - 1, lantern.Y + lantern.H} {
+			if y >= 0 && y < len(sc.Styles) && lantern.X+dx >= 0 && sc.Styles[y][lantern.X+dx].Lamp == buildings.LampError {
+				glow = true
+			}
+		}
+	}
+	if !glow {
+		t.Fatalf("expected an error glow around the lantern")
+	}
+	if sc.Styles[other.Y][other.X].Lamp != buildings.LampDark {
+		t.Fatalf("a cottage should not be lit")
+	}
+}
+
+func TestLayoutCacheFollowsTheTree(t *testing.T) {
+	repo := mockRepoDir("pkg", "")
+	cache := &LayoutCache{}
+	opts := Options{Unicode: true, Bounds: &MapBounds{MinW: 32, MinH: 16}, Layout: cache}
+	has := func(sc Scene, path string) bool {
+		for _, s := range sc.Slots {
+			if s.Path == path {
+				return true
+			}
+		}
+		return false
+	}
+
+	first := DeriveWithOptions(repo, 80, 24, opts)
+	// a node linked by hand does not bump the generation, so the cached
+	// layout is kept
+	hidden := &domain.FileNode{Path: "/pkg/hidden.go", Name: "hidden.go", Ext: ".go"}
+	repo.Index["/pkg"].Children = append(repo.Index["/pkg"].Children, hidden)
+	second := DeriveWithOptions(repo, 80, 24, opts)
+	if has(second, hidden.Path) || len(second.Slots) != len(first.Slots) || &second.Slots[0] != &first.Slots[0] {
+		t.Fatalf("unchanged tree was laid out again")
+	}
+
+	repo.ApplyEvents([]domain.FsEvent{{Path: "/pkg/main.go", Kind: domain.Create}}, func(path string) *domain.FileNode {
+		return &domain.FileNode{Path: path, Name: "main.go", Ext: ".go"}
+	})
+	third := DeriveWithOptions(repo, 80, 24, opts)
+	if !has(third, "/pkg/main.go") {
+		t.Fatalf("created file missing from the layout")
+	}
+	opts.Bounds = &MapBounds{MinW: 64, MinH: 32}
+	if wider := DeriveWithOptions(repo, 80, 24, opts); wider.MapW != 64 || wider.MapH != 32 {
+		t.Fatalf("map is %dx%d after the bounds grew, want 64x32", wider.MapW, wider.MapH)
+	}
+}
@@ -0,0 +1,56 @@
+// internal/scene/size.go
+package scene
+
+import (
+	"math"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/layout"
+)
+
+// mapSlack leaves room for district walls, roads and partially filled rows
+const mapSlack = 3
+
+// MapSize picks virtual map dimensions that grow with the tree and follow
+// the terminal's aspect ratio (the fixed map's ratio when the terminal size
+// is unknown). Sizes are rounded to coarse steps so small changes to the
+// tree do not reshuffle the whole layout; the same tree and terminal always
+// produce the same size.
+func MapSize(root *domain.FileNode, viewCols, viewRows int, b MapBounds) (int, int) {
+	aspect := float64(VirtualMapWidth) / float64(VirtualMapHeight)
+	if viewCols > 0 && viewRows > 0 {
+		aspect = float64(viewCols) / float64(viewRows)
+	}
+	area := float64(layout.EstimateArea(root)) * mapSlack
+	w := math.Sqrt(area * aspect)
+	h := area / math.Max(w, 1)
+
+	width := roundUp(int(math.Ceil(w)), 8)
+	height := roundUp(int(math.Ceil(h)), 4)
+	width = clampDim(width, b.MinW, b.MaxW)
+	height = clampDim(height, b.MinH, b.MaxH)
+	return width, height
+}
+
+// BoundsFromConfig converts the `render.map` section of village.yml
+func BoundsFromConfig(c config.MapCfg) MapBounds {
+	return MapBounds{MinW: c.MinWidth, MinH: c.MinHeight, MaxW: c.MaxWidth, MaxH: c.MaxHeight}
+}
+
+func roundUp(v, step int) int {
+	return (v + step - 1) / step * step
+}
+
+func clampDim(v, lo, hi int) int {
+	if lo > 0 && v < lo {
+		v = lo
+	}
+	if hi > 0 && v > hi {
+		v = hi
+	}
+	if v < 8 {
+		v = 8
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package scene
+
+import (
+	"fmt"
+	"testing"
+
+	"example.com/village-watch/internal/domain"
+)
+
+func mockRepoFiles(n int) *domain.RepoState {
+	r := domain.NewRepo("/")
+	root := &domain.FileNode{Path: "/", Name: "/", IsDir: true}
+	r.Root = root
+	r.Upsert(root)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("f%05d.go", i)
+		f := &domain.FileNode{Path: "/" + name, Name: name}
+		r.Upsert(f)
+		root.Children = append(root.Children, f)
+	}
+	return r
+}
+
+func TestMapSizeGrowsWithinBounds(t *testing.T) {
+	bounds := MapBounds{MinW: 64, MinH: 24, MaxW: 512, MaxH: 256}
+	small := mockRepoFiles(5)
+	w, h := MapSize(small.Root, 0, 0, bounds)
+	if w != 64 || h != 24 {
+		t.Fatalf("5 files: got %dx%d, want the minimum 64x24", w, h)
+	}
+
+	mid := mockRepoFiles(400)
+	mw, mh := MapSize(mid.Root, 0, 0, bounds)
+	if mw <= w || mh <= h {
+		t.Fatalf("400 files: got %dx%d, expected larger than %dx%d", mw, mh, w, h)
+	}
+
+	huge := mockRepoFiles(10000)
+	if hw, hh := MapSize(huge.Root, 0, 0, bounds); hw != 512 || hh != 256 {
+		t.Fatalf("10k files: got %dx%d, want the maximum 512x256", hw, hh)
+	}
+
+	// wide terminals get wide maps, and results are stable
+	ww, wh := MapSize(mid.Root, 200, 40, MapBounds{})
+	if ww <= wh*2 {
+		t.Fatalf("wide terminal: got %dx%d", ww, wh)
+	}
+	if w2, h2 := MapSize(mid.Root, 200, 40, MapBounds{}); w2 != ww || h2 != wh {
+		t.Fatalf("non-deterministic size")
+	}
+}
+
+func TestDeriveWithBoundsSizesVirtualMap(t *testing.T) {
+	repo := mockRepoFiles(400)
+	bounds := MapBounds{MinW: 64, MinH: 24, MaxW: 512, MaxH: 256}
+	sc := DeriveWithOptions(repo, 80, 24, Options{Unicode: true, Bounds: &bounds})
+	if len(sc.VirtualMap) != sc.MapH || len(sc.VirtualMap[0]) != sc.MapW {
+		t.Fatalf("virtual map %dx%d does not match MapW/MapH %dx%d", len(sc.VirtualMap[0]), len(sc.VirtualMap), sc.MapW, sc.MapH)
+	}
+	if len(sc.Canvas) != 24 {
+		t.Fatalf("canvas height = %d, want 24", len(sc.Canvas))
+	}
+}
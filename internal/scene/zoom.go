@@ -0,0 +1,231 @@
+// internal/scene/zoom.go
+package scene
+
+import (
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/domain"
+)
+
+// MaxZoom is the most zoomed-out level; level 0 shows one map cell per
+// terminal cell
+const MaxZoom = 3
+
+// Viewport is the map cell shown at the top-left corner of the view
+type Viewport struct{ X, Y int }
+
+// ZoomScale returns how many map columns and rows one terminal cell covers at
+// a zoom level: half blocks at level 1, braille dots from level 2
+func ZoomScale(zoom int) (int, int) {
+	switch clampZoom(zoom) {
+	case 1:
+		return 1, 2
+	case 2:
+		return 2, 4
+	case 3:
+		return 4, 8
+	default:
+		return 1, 1
+	}
+}
+
+func clampZoom(zoom int) int {
+	if zoom < 0 {
+		return 0
+	}
+	if zoom > MaxZoom {
+		return MaxZoom
+	}
+	return zoom
+}
+
+// SetViewport moves the view to x, y (in map cells), clamped to the map, and
+// re-extracts the canvas
+func (s *Scene) SetViewport(x, y int) {
+	if s == nil || s.VirtualMap == nil {
+		return
+	}
+	s.ViewportX, s.ViewportY = clampViewport(x, y, s.W, s.H, s.MapW, s.MapH, s.Zoom)
+	s.project()
+}
+
+// SetZoom changes the zoom level keeping the center of the view in place
+func (s *Scene) SetZoom(zoom int) {
+	if s == nil || s.VirtualMap == nil {
+		return
+	}
+	zoom = clampZoom(zoom)
+	kx, ky := ZoomScale(s.Zoom)
+	cx := s.ViewportX + s.W*kx/2
+	cy := s.ViewportY + s.H*ky/2
+	s.Zoom = zoom
+	kx, ky = ZoomScale(zoom)
+	s.SetViewport(cx-s.W*kx/2, cy-s.H*ky/2)
+}
+
+// clampViewport keeps a view of cols x rows terminal cells inside the map;
+// an axis on which the map fits entirely is pinned to 0 and shown centered
+func clampViewport(x, y, cols, rows, mapW, mapH, zoom int) (int, int) {
+	kx, ky := ZoomScale(zoom)
+	return clampAxis(x, cols*kx, mapW), clampAxis(y, rows*ky, mapH)
+}
+
+func clampAxis(v, view, mapSize int) int {
+	if view >= mapSize || v < 0 {
+		return 0
+	}
+	if v > mapSize-view {
+		return mapSize - view
+	}
+	return v
+}
+
+// project refreshes Canvas and CanvasStyles from the virtual map
+func (s *Scene) project() {
+	if s.Zoom == 0 {
+		s.Canvas, s.CanvasStyles = extractViewport(s.VirtualMap, s.Styles, s.W, s.H, s.ViewportX, s.ViewportY)
+		return
+	}
+	s.Canvas, s.CanvasStyles = extractZoomed(s.VirtualMap, s.Styles, s.W, s.H, s.ViewportX, s.ViewportY, s.Zoom, s.Unicode)
+}
+
+// asciiDensity shades a block by the share of built cells in it
+var asciiDensity = []rune(" .:#")
+
+// extractZoomed downsamples the map into a viewWidth x viewHeight canvas.
+// Each terminal cell covers a block of map cells: its glyph shows which of
+// them are built on (anything but ground) and its style is that of the most
+// important cell in the block.
+func extractZoomed(virtualMap [][]rune, styles [][]buildings.CellStyle, viewWidth, viewHeight, viewportX, viewportY, zoom int, unicode bool) ([]string, [][]buildings.CellStyle) {
+	mapH := len(virtualMap)
+	mapW := 0
+	if mapH > 0 {
+		mapW = len(virtualMap[0])
+	}
+	kx, ky := ZoomScale(zoom)
+	originX := viewOrigin(viewWidth*kx, mapW, viewportX)
+	originY := viewOrigin(viewHeight*ky, mapH, viewportY)
+	built := func(x, y int) bool {
+		return x >= 0 && x < mapW && y >= 0 && y < mapH && styles != nil && styles[y][x].Role != buildings.RoleGround
+	}
+
+	canvas := make([]string, viewHeight)
+	canvasStyles := buildings.NewStyleGrid(viewWidth, viewHeight)
+	for y := 0; y < viewHeight; y++ {
+		line := make([]rune, viewWidth)
+		for x := 0; x < viewWidth; x++ {
+			bx, by := originX+x*kx, originY+y*ky
+			canvasStyles[y][x] = BlockStyle(styles, bx, by, kx, ky)
+			switch {
+			case !unicode:
+				n := 0
+				for dy := 0; dy < ky; dy++ {
+					for dx := 0; dx < kx; dx++ {
+						if built(bx+dx, by+dy) {
+							n++
+						}
+					}
+				}
+				line[x] = asciiDensity[(n*(len(asciiDensity)-1)+kx*ky-1)/(kx*ky)]
+			case zoom == 1:
+				line[x] = halfBlock(built(bx, by), built(bx, by+1))
+			default:
+				// one braille dot per (kx/2) x (ky/4) sub-block
+				sx, sy := kx/2, ky/4
+				line[x] = braille(func(dx, dy int) bool {
+					for yy := 0; yy < sy; yy++ {
+						for xx := 0; xx < sx; xx++ {
+							if built(bx+dx*sx+xx, by+dy*sy+yy) {
+								return true
+							}
+						}
+					}
+					return false
+				})
+			}
+		}
+		canvas[y] = string(line)
+	}
+	return canvas, canvasStyles
+}
+
+func halfBlock(top, bottom bool) rune {
+	switch {
+	case top && bottom:
+		return '█'
+	case top:
+		return '▀'
+	case bottom:
+		return '▄'
+	default:
+		return ' '
+	}
+}
+
+// brailleDots maps a dot at column x, row y of a braille cell to its bit
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// braille builds a braille character from a 2x4 dot pattern
+func braille(dot func(x, y int) bool) rune {
+	r := rune(0x2800)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 2; x++ {
+			if dot(x, y) {
+				r |= brailleDots[y][x]
+			}
+		}
+	}
+	return r
+}
+
+// BlockStyle picks the style of the most important cell in the kx x ky block
+// at bx, by: the cursor, search matches, animations, lantern light, git
+// banners, then buildings, labels, roads or filtered-out cells and finally
+// ground
+func BlockStyle(styles [][]buildings.CellStyle, bx, by, kx, ky int) buildings.CellStyle {
+	var best buildings.CellStyle
+	bestRank := -1
+	for y := by; y < by+ky; y++ {
+		if y < 0 || y >= len(styles) {
+			continue
+		}
+		for x := bx; x < bx+kx; x++ {
+			if x < 0 || x >= len(styles[y]) {
+				continue
+			}
+			if r := styleRank(styles[y][x]); r > bestRank {
+				best, bestRank = styles[y][x], r
+			}
+		}
+	}
+	return best
+}
+
+func styleRank(st buildings.CellStyle) int {
+	switch {
+	case st.Cursor:
+		return 8
+	case st.Highlight:
+		return 7
+	case st.State != domain.StateNormal:
+		return 6
+	case st.Lamp >= buildings.LampWarn:
+		return 5
+	case st.Dim:
+		return 1
+	case st.Role == buildings.RoleLabel:
+		return 2
+	case st.Role == buildings.RoleRoad:
+		return 1
+	case st.Role == buildings.RoleGround:
+		return 0
+	case st.Git != domain.GitClean, st.Lamp != buildings.LampDark:
+		return 4
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,86 @@
+package scene
+
+import (
+	"testing"
+
+	"example.com/village-watch/internal/buildings"
+)
+
+func TestClampViewport(t *testing.T) {
+	tests := []struct {
+		name         string
+		x, y, zoom   int
+		wantX, wantY int
+	}{
+		{"inside", 10, 5, 0, 10, 5},
+		{"negative", -3, -1, 0, 0, 0},
+		{"past edge", 500, 500, 0, 128 - 40, 60 - 10},
+		{"zoom 1 rows", 0, 500, 1, 0, 60 - 20},
+		{"map fits at zoom 3", 7, 7, 3, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := clampViewport(tt.x, tt.y, 40, 10, 128, 60, tt.zoom)
+			if x != tt.wantX || y != tt.wantY {
+				t.Fatalf("got %d,%d, want %d,%d", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestExtractZoomed(t *testing.T) {
+	// 4x8 map with the top-left 2x4 block and the bottom-right cell built
+	grid := make([][]rune, 8)
+	styles := buildings.NewStyleGrid(4, 8)
+	for y := range grid {
+		grid[y] = []rune("....")
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 2; x++ {
+			styles[y][x] = buildings.CellStyle{Role: buildings.RoleWall, Archetype: buildings.Library}
+		}
+	}
+	styles[7][3] = buildings.CellStyle{Role: buildings.RoleRoad}
+
+	tests := []struct {
+		name    string
+		zoom    int
+		unicode bool
+		want    []string
+	}{
+		{"half blocks", 1, true, []string{"██  ", "██  ", "    ", "   ▄"}},
+		{"braille", 2, true, []string{"⣿⠀", "⠀⢀"}},
+		{"ascii density", 2, false, []string{"# ", " ."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kx, ky := ZoomScale(tt.zoom)
+			canvas, cs := extractZoomed(grid, styles, 4/kx, 8/ky, 0, 0, tt.zoom, tt.unicode)
+			for i := range tt.want {
+				if canvas[i] != tt.want[i] {
+					t.Fatalf("line %d = %q, want %q", i, canvas[i], tt.want[i])
+				}
+			}
+			if cs[0][0].Archetype != buildings.Library {
+				t.Fatalf("top-left style = %+v, want the library wall", cs[0][0])
+			}
+		})
+	}
+}
+
+func TestSetZoomKeepsCenter(t *testing.T) {
+	repo := mockRepoFiles(400)
+	bounds := MapBounds{MinW: 64, MinH: 24, MaxW: 512, MaxH: 256}
+	sc := DeriveWithOptions(repo, 20, 10, Options{Unicode: true, Bounds: &bounds})
+	cx, cy := sc.ViewportX+10, sc.ViewportY+5
+	sc.SetZoom(1)
+	if sc.Zoom != 1 {
+		t.Fatalf("zoom = %d, want 1", sc.Zoom)
+	}
+	if gx, gy := sc.ViewportX+10, sc.ViewportY+10; gx != cx || gy != cy {
+		t.Fatalf("center moved from %d,%d to %d,%d", cx, cy, gx, gy)
+	}
+	if len(sc.Canvas) != 10 || len([]rune(sc.Canvas[0])) != 20 {
+		t.Fatalf("canvas is %dx%d, want 20x10", len([]rune(sc.Canvas[0])), len(sc.Canvas))
+	}
+}
@@ -0,0 +1,101 @@
+// internal/search/search.go
+package search
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"example.com/village-watch/internal/domain"
+)
+
+// Scoring of a fuzzy match; higher is better
+const (
+	matchBonus       = 16 // every matched rune
+	consecutiveBonus = 24 // matched rune right after the previous one
+	boundaryBonus    = 32 // matched rune starting a path segment or word
+	basenameBonus    = 8  // matched rune inside the file name
+	gapPenalty       = 1  // every skipped rune between matches
+)
+
+// Score fuzzy-matches query against s: every rune of query must appear in s
+// in order, case-insensitively. Runs of adjacent runes, segment starts and
+// matches in the last path element score higher.
+func Score(query, s string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, false
+	}
+	r := []rune(strings.ToLower(s))
+	base := 0
+	for i, c := range r {
+		if c == '/' {
+			base = i + 1
+		}
+	}
+
+	score, qi, last := 0, 0, -1
+	for i := 0; i < len(r) && qi < len(q); i++ {
+		if r[i] != q[qi] {
+			continue
+		}
+		score += matchBonus
+		switch {
+		case last >= 0 && i == last+1:
+			score += consecutiveBonus
+		case last >= 0:
+			score -= gapPenalty * (i - last - 1)
+		}
+		if i == 0 || isBoundary(r[i-1]) {
+			score += boundaryBonus
+		}
+		if i >= base {
+			score += basenameBonus
+		}
+		last = i
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
+
+func isBoundary(r rune) bool {
+	return r == '/' || r == '_' || r == '-' || r == '.' || unicode.IsSpace(r)
+}
+
+// Match returns the Index paths whose path relative to the repository root
+// fuzzy-matches query, best match first
+func Match(repo *domain.RepoState, query string) []string {
+	if repo == nil || strings.TrimSpace(query) == "" {
+		return nil
+	}
+	type hit struct {
+		path, rel string
+		score     int
+	}
+	var hits []hit
+	for path := range repo.Index {
+		rel, err := filepath.Rel(repo.RootPath, path)
+		if err != nil || rel == "." {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if score, ok := Score(query, rel); ok {
+			hits = append(hits, hit{path, rel, score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].rel < hits[j].rel
+	})
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.path
+	}
+	return out
+}
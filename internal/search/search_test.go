@@ -0,0 +1,59 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"example.com/village-watch/internal/domain"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		query, s string
+		ok       bool
+	}{
+		{"main", "cmd/village-watch/main.go", true},
+		{"MAIN", "cmd/village-watch/main.go", true},
+		{"cvm", "cmd/village-watch/main.go", true},
+		{"mainx", "cmd/village-watch/main.go", false},
+		{"niam", "main.go", false},
+		{"", "main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if _, ok := Score(tt.query, tt.s); ok != tt.ok {
+				t.Fatalf("Score(%q, %q) ok = %v, want %v", tt.query, tt.s, ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScorePrefersTighterMatches(t *testing.T) {
+	better := []struct{ query, hi, lo string }{
+		{"scene", "internal/scene/scene.go", "internal/search/scanner_test.go"},
+		{"rg", "internal/render/go.go", "internal/ragged.go"},
+		{"model", "internal/ui/model.go", "internal/model/readme.md"},
+	}
+	for _, tt := range better {
+		hi, _ := Score(tt.query, tt.hi)
+		lo, _ := Score(tt.query, tt.lo)
+		if hi <= lo {
+			t.Errorf("%q: %s scored %d, not above %s (%d)", tt.query, tt.hi, hi, tt.lo, lo)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	repo := domain.NewRepo("/r")
+	for _, p := range []string{"/r", "/r/README.md", "/r/internal", "/r/internal/render.go", "/r/internal/ui/model.go"} {
+		repo.Upsert(&domain.FileNode{Path: p})
+	}
+	got := Match(repo, "re")
+	want := []string{"/r/README.md", "/r/internal/render.go", "/r/internal/ui/model.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Match = %v, want %v", got, want)
+	}
+	if got := Match(repo, "  "); got != nil {
+		t.Fatalf("blank query matched %v", got)
+	}
+}
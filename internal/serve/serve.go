@@ -0,0 +1,271 @@
+// internal/serve/serve.go
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/export"
+	"example.com/village-watch/internal/live"
+	"example.com/village-watch/internal/render"
+	"example.com/village-watch/internal/scene"
+)
+
+// clientBuffer is how many frames a viewer may fall behind before it is
+// dropped; its browser reconnects and starts again from a full frame
+const clientBuffer = 16
+
+// liveScript follows the frames pushed over /events: the first one and any
+// after a reconnect carry every row, later ones only the rows that changed
+const liveScript = `<script>
+const colors = document.getElementById("colors");
+const status = document.getElementById("status");
+let rows = [];
+const events = new EventSource("events");
+events.onmessage = e => {
+  const f = JSON.parse(e.data);
+  if (f.css) colors.textContent = f.css;
+  if (f.status) status.textContent = f.status;
+  if (f.full || f.height !== rows.length) {
+    village.textContent = "";
+    rows = [];
+    for (let y = 0; y < f.height; y++) {
+      if (y > 0) village.append("\n");
+      const row = document.createElement("span");
+      village.append(row);
+      rows.push(row);
+    }
+  }
+  for (const [y, html] of Object.entries(f.rows || {})) rows[y].innerHTML = html;
+};
+</script>
+`
+
+// Frame is one message on /events: the whole village when Full is set, or
+// the rows that changed since the previous frame. CSS and Status are left
+// out when they did not change.
+type Frame struct {
+	Full   bool           `json:"full,omitempty"`
+	Height int            `json:"height"`
+	CSS    string         `json:"css,omitempty"`
+	Status string         `json:"status,omitempty"`
+	Rows   map[int]string `json:"rows,omitempty"`
+}
+
+// Server shows a live village to any number of browsers. One Run loop
+// derives the scene at the configured FPS and pushes what changed to every
+// viewer over Server-Sent Events.
+type Server struct {
+	village  *live.Village
+	cfg      config.Config
+	renderer *buildings.Renderer
+	theme    render.Theme
+	layouts  scene.LayoutCache // only touched by Run
+
+	mu      sync.Mutex
+	markup  export.Markup // the frame on screen
+	status  string
+	clients map[chan []byte]bool
+}
+
+// New serves the village in the given theme
+func New(v *live.Village, cfg config.Config, renderer *buildings.Renderer, theme render.Theme) *Server {
+	return &Server{village: v, cfg: cfg, renderer: renderer, theme: theme, clients: map[chan []byte]bool{}}
+}
+
+// Handler serves the page at /, its frames at /events and the whole current
+// frame as JSON at /frame.json
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.page)
+	mux.HandleFunc("/events", s.events)
+	mux.HandleFunc("/frame.json", s.frameJSON)
+	return mux
+}
+
+// Run renders frames until ctx is done
+func (s *Server) Run(ctx context.Context) {
+	t := time.NewTicker(time.Second / time.Duration(max(1, s.cfg.FPS)))
+	defer t.Stop()
+	for {
+		s.step()
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// step derives the scene of the whole map and pushes it if it changed
+func (s *Server) step() {
+	var sc scene.Scene
+	bounds := scene.BoundsFromConfig(s.cfg.Render.Map)
+	s.village.Frame(func(repo *domain.RepoState) {
+		mapW, mapH := scene.MapSize(repo.Root, 0, 0, bounds)
+		sc = scene.DeriveWithOptions(repo, mapW, mapH, scene.Options{
+			// no FPS: the status would change with every frame
+			Unicode: s.cfg.Render.Unicode, Renderer: s.renderer, Bounds: &bounds, Layout: &s.layouts,
+		})
+	})
+	m, err := export.NewMarkup(sc, s.theme, export.DocOptions{Full: true, Root: s.village.Root()})
+	if err != nil {
+		return
+	}
+	s.publish(m, sc.Status)
+}
+
+// publish makes m the frame on screen and sends every viewer the rows that
+// changed
+func (s *Server) publish(m export.Markup, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := Frame{Height: len(m.Rows), Rows: map[int]string{}}
+	if m.CSS != s.markup.CSS {
+		f.CSS = m.CSS
+	}
+	if status != s.status {
+		f.Status = status
+	}
+	for y, row := range m.Rows {
+		if y >= len(s.markup.Rows) || s.markup.Rows[y] != row {
+			f.Rows[y] = row
+		}
+	}
+	changed := f.CSS != "" || f.Status != "" || len(f.Rows) > 0 || len(m.Rows) != len(s.markup.Rows)
+	s.markup, s.status = m, status
+	if !changed || len(s.clients) == 0 {
+		return
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return
+	}
+	for c := range s.clients {
+		select {
+		case c <- data:
+		default:
+			// too far behind: let it reconnect for a full frame
+			delete(s.clients, c)
+			close(c)
+		}
+	}
+}
+
+// full is the whole frame on screen; callers hold mu
+func (s *Server) full() Frame {
+	f := Frame{Full: true, Height: len(s.markup.Rows), CSS: s.markup.CSS, Status: s.status, Rows: map[int]string{}}
+	for y, row := range s.markup.Rows {
+		f.Rows[y] = row
+	}
+	return f
+}
+
+func (s *Server) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	p := export.Page{
+		Title:  filepath.Base(s.village.Root()) + " village",
+		Markup: s.markup,
+		Status: s.status,
+		Script: liveScript,
+	}
+	s.mu.Unlock()
+	if p.Status == "" {
+		// the script fills it in from the first frame
+		p.Status = " "
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = p.Write(w)
+}
+
+func (s *Server) frameJSON(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	f := s.full()
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(f)
+}
+
+// events streams frames to one viewer, starting with the whole village
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	c := make(chan []byte, clientBuffer)
+	s.mu.Lock()
+	first, err := json.Marshal(s.full())
+	s.clients[c] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	send := func(data []byte) bool {
+		_, err := fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+		return err == nil
+	}
+	if !send(first) {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data, ok := <-c:
+			if !ok || !send(data) {
+				return
+			}
+		}
+	}
+}
+
+// ListenAndServe serves the village on addr until ctx is done
+func ListenAndServe(ctx context.Context, addr string, s *Server) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// event streams end with ctx, so shutdown does not wait on them
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go s.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdown, done := context.WithTimeout(context.Background(), time.Second)
+		defer done()
+		_ = srv.Shutdown(shutdown)
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package serve
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/live"
+	"example.com/village-watch/internal/render"
+)
+
+func TestServeFollowsWatcher(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Git.Enabled, cfg.Logs.Enabled = false, false
+	cfg.Watch.DebounceMS = 20
+	v, err := live.Start(root, cfg, buildings.NewRenderer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+	s := New(v, cfg, buildings.NewRenderer(), render.ThemeByName("forest"))
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	// cancelled first, ending the event streams the server waits on
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), `new EventSource("events")`) {
+		t.Fatalf("page lacks the event stream:\n%s", page)
+	}
+
+	// two viewers follow the one watcher
+	a, b := stream(t, ctx, srv.URL), stream(t, ctx, srv.URL)
+	for _, frames := range []chan Frame{a, b} {
+		f := next(t, frames, func(f Frame) bool { return f.Full })
+		if f.Height == 0 || len(f.Rows) != f.Height {
+			t.Fatalf("full frame has %d of %d rows", len(f.Rows), f.Height)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "util.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, frames := range []chan Frame{a, b} {
+		f := next(t, frames, func(f Frame) bool { return strings.Contains(f.Status, "New: 1") })
+		if f.Full || len(f.Rows) == 0 || len(f.Rows) == f.Height {
+			t.Fatalf("want a diff of some rows, got %d of %d (full %v)", len(f.Rows), f.Height, f.Full)
+		}
+	}
+
+	resp, err = http.Get(srv.URL + "/frame.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f Frame
+	err = json.NewDecoder(resp.Body).Decode(&f)
+	resp.Body.Close()
+	if err != nil || !f.Full || !strings.Contains(strings.Join(mapValues(f.Rows), ""), `data-path="util.go"`) {
+		t.Fatalf("frame.json = %+v, %v", f, err)
+	}
+}
+
+// stream reads the frames of /events until ctx is done
+func stream(t *testing.T, ctx context.Context, url string) chan Frame {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+	frames := make(chan Frame, 64)
+	go func() {
+		defer resp.Body.Close()
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			data, ok := strings.CutPrefix(sc.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var f Frame
+			if json.Unmarshal([]byte(data), &f) == nil {
+				select {
+				case frames <- f:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return frames
+}
+
+func next(t *testing.T, frames chan Frame, want func(Frame) bool) Frame {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case f := <-frames:
+			if want(f) {
+				return f
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for a frame")
+		}
+	}
+}
+
+func mapValues(m map[int]string) []string {
+	var out []string
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
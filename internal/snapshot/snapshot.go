@@ -0,0 +1,252 @@
+// internal/snapshot/snapshot.go
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/scene"
+)
+
+// Version is the schema written by Write; Read rejects any other
+const Version = 1
+
+// Snapshot is a village as JSON: the tree with its animation, git and log
+// states, the activity counters and, when taken from a scene, the layout the
+// scene computed. Paths are relative to Root and slash-separated; the root
+// itself is ".".
+type Snapshot struct {
+	Version int       `json:"version"`
+	Root    string    `json:"root"` // the directory the village showed
+	Taken   time.Time `json:"taken"`
+	Stats   Stats     `json:"stats"`
+	Nodes   []Node    `json:"nodes"` // directories before what they hold
+	Layout  *Layout   `json:"layout,omitempty"`
+}
+
+// Stats counts the watcher events seen before the snapshot
+type Stats struct {
+	New      int `json:"new"`
+	Modified int `json:"modified"`
+	Deleted  int `json:"deleted"`
+}
+
+// Node is one file or directory
+type Node struct {
+	Path    string    `json:"path"`
+	Dir     bool      `json:"dir,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	State   *State    `json:"state,omitempty"`   // the animation playing, if any
+	Git     string    `json:"git,omitempty"`     // untracked|staged|modified|conflicted; directories carry the most pressing below
+	Log     *Log      `json:"log,omitempty"`     // lanterns lit by their logs
+	History []Event   `json:"history,omitempty"` // oldest first
+}
+
+// State is an animation and the time it plays
+type State struct {
+	Kind  string    `json:"kind"` // new|modified|deleted
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+// Log is what the tailer last read from a lantern's log
+type Log struct {
+	LinesPerSec float64 `json:"lines_per_sec"`
+	Errors      int     `json:"errors"`
+	Warnings    int     `json:"warnings"`
+}
+
+// Event is a watcher event in a node's history
+type Event struct {
+	Kind string    `json:"kind"` // created|modified|removed|renamed
+	When time.Time `json:"when"`
+}
+
+// Layout is where the scene put every slot on its virtual map
+type Layout struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Slots  []Slot `json:"slots"` // parents first
+}
+
+// Slot is a building, district or hamlet on the map
+type Slot struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"` // building|district|hamlet
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	W      int    `json:"w"`
+	H      int    `json:"h"`
+	Depth  int    `json:"depth"`            // nesting of the enclosing district
+	Hidden int    `json:"hidden,omitempty"` // hamlets: files folded into the slot
+}
+
+// Take records repo and, when sc is not nil, the layout of its map
+func Take(repo *domain.RepoState, sc *scene.Scene) Snapshot {
+	s := Snapshot{
+		Version: Version,
+		Root:    repo.RootPath,
+		Taken:   time.Now(),
+		Stats:   Stats{New: repo.Stats.NewFiles, Modified: repo.Stats.Modified, Deleted: repo.Stats.Deleted},
+	}
+	var walk func(n *domain.FileNode)
+	walk = func(n *domain.FileNode) {
+		s.Nodes = append(s.Nodes, s.node(repo, n))
+		for _, ch := range repo.SortedChildren(n) {
+			walk(ch)
+		}
+	}
+	if repo.Root != nil {
+		walk(repo.Root)
+	}
+	if sc != nil {
+		s.Layout = &Layout{Width: sc.MapW, Height: sc.MapH, Slots: make([]Slot, 0, len(sc.Slots))}
+		for _, sl := range sc.Slots {
+			s.Layout.Slots = append(s.Layout.Slots, Slot{
+				Path: s.rel(sl.Path), Kind: sl.Kind.String(),
+				X: sl.X, Y: sl.Y, W: sl.W, H: sl.H, Depth: sl.Depth, Hidden: sl.Hidden,
+			})
+		}
+	}
+	return s
+}
+
+func (s Snapshot) node(repo *domain.RepoState, n *domain.FileNode) Node {
+	out := Node{Path: s.rel(n.Path), Dir: n.IsDir, Size: n.Size, ModTime: n.ModTime}
+	if n.IsStateActive() {
+		out.State = &State{Kind: n.State.String(), Since: n.StateTime, Until: n.StateExpiry}
+	}
+	if n.Git != domain.GitClean {
+		out.Git = n.Git.String()
+	}
+	if n.Log != (domain.LogActivity{}) {
+		out.Log = &Log{LinesPerSec: n.Log.LinesPerSec, Errors: n.Log.Errors, Warnings: n.Log.Warnings}
+	}
+	for _, e := range repo.History[n.Path] {
+		out.History = append(out.History, Event{Kind: e.Kind.String(), When: e.When})
+	}
+	return out
+}
+
+// rel is path relative to the root, as written to snapshots
+func (s Snapshot) rel(path string) string {
+	rel, err := filepath.Rel(s.Root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// abs is the path of a snapshot node under the root
+func (s Snapshot) abs(rel string) string {
+	if rel == "." {
+		return s.Root
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(rel))
+}
+
+// MapBounds pins the map to the size the layout was taken at, so that the
+// tree lays out the same in any terminal; ok is false without a layout
+func (s Snapshot) MapBounds() (b scene.MapBounds, ok bool) {
+	if s.Layout == nil {
+		return scene.MapBounds{}, false
+	}
+	w, h := s.Layout.Width, s.Layout.Height
+	return scene.MapBounds{MinW: w, MinH: h, MaxW: w, MaxH: h}, true
+}
+
+// Write writes s as indented JSON
+func Write(w io.Writer, s Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Read decodes a snapshot of this Version
+func Read(r io.Reader) (Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot: %w", err)
+	}
+	if s.Version != Version {
+		return Snapshot{}, fmt.Errorf("snapshot version %d, want %d", s.Version, Version)
+	}
+	return s, nil
+}
+
+// Load reads the snapshot in the named file
+func Load(name string) (Snapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Repo rebuilds the tree. Animations resume where they were when the
+// snapshot was taken, however long ago that was.
+func (s Snapshot) Repo() (*domain.RepoState, error) {
+	if len(s.Nodes) == 0 || s.Nodes[0].Path != "." || !s.Nodes[0].Dir {
+		return nil, errors.New("snapshot: the first node must be the root directory")
+	}
+	repo := domain.NewRepo(s.Root)
+	repo.Ruins = domain.DemolitionTime
+	repo.Stats = domain.ActivityStats{NewFiles: s.Stats.New, Modified: s.Stats.Modified, Deleted: s.Stats.Deleted}
+	shift := time.Since(s.Taken)
+	git := map[string]domain.GitState{}
+	for i, sn := range s.Nodes {
+		path := s.abs(sn.Path)
+		if _, dup := repo.Index[path]; dup {
+			return nil, fmt.Errorf("snapshot: node %q listed twice", sn.Path)
+		}
+		n := &domain.FileNode{Path: path, Name: filepath.Base(path), IsDir: sn.Dir, Size: sn.Size, ModTime: sn.ModTime}
+		if !n.IsDir {
+			n.Ext = domain.Ext(n.Name)
+		}
+		if i == 0 {
+			repo.Root = n
+		} else {
+			parent := repo.Index[filepath.Dir(path)]
+			if parent == nil || !parent.IsDir {
+				return nil, fmt.Errorf("snapshot: node %q comes before its directory", sn.Path)
+			}
+			parent.Children = append(parent.Children, n)
+		}
+		repo.Upsert(n)
+		if sn.State != nil {
+			state, err := domain.ParseFileState(sn.State.Kind)
+			if err != nil {
+				return nil, fmt.Errorf("snapshot: %s: %w", sn.Path, err)
+			}
+			n.State, n.StateTime, n.StateExpiry = state, sn.State.Since.Add(shift), sn.State.Until.Add(shift)
+		}
+		if sn.Git != "" && !n.IsDir {
+			g, err := domain.ParseGitState(sn.Git)
+			if err != nil {
+				return nil, fmt.Errorf("snapshot: %s: %w", sn.Path, err)
+			}
+			git[path] = g
+		}
+		if sn.Log != nil {
+			n.Log = domain.LogActivity{LinesPerSec: sn.Log.LinesPerSec, Errors: sn.Log.Errors, Warnings: sn.Log.Warnings}
+		}
+		for _, e := range sn.History {
+			kind, err := domain.ParseEventKind(e.Kind)
+			if err != nil {
+				return nil, fmt.Errorf("snapshot: %s: %w", sn.Path, err)
+			}
+			repo.RecordEvent(domain.FsEvent{Path: path, Kind: kind, When: e.When})
+		}
+	}
+	// directories and the summary follow from the files
+	repo.ApplyGit(git)
+	return repo, nil
+}
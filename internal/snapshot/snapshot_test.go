@@ -0,0 +1,109 @@
+package snapshot
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/scene"
+)
+
+func testRepo() *domain.RepoState {
+	r := domain.NewRepo("/r")
+	r.Root = &domain.FileNode{Path: "/r", Name: "r", IsDir: true}
+	r.Upsert(r.Root)
+	pkg := &domain.FileNode{Path: "/r/pkg", Name: "pkg", IsDir: true}
+	r.Root.Children = append(r.Root.Children, pkg)
+	r.Upsert(pkg)
+	for _, path := range []string{"/r/main.go", "/r/pkg/util.go", "/r/pkg/app.log"} {
+		n := &domain.FileNode{Path: path, Size: 42, ModTime: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		n.Name = path[strings.LastIndex(path, "/")+1:]
+		n.Ext = domain.Ext(n.Name)
+		parent := r.Root
+		if strings.HasPrefix(path, "/r/pkg/") {
+			parent = pkg
+		}
+		parent.Children = append(parent.Children, n)
+		r.Upsert(n)
+	}
+	r.Stats = domain.ActivityStats{NewFiles: 1, Modified: 2}
+	r.RecordEvent(domain.FsEvent{Path: "/r/pkg/util.go", Kind: domain.Create, When: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)})
+	r.SetFileState("/r/pkg/util.go", domain.StateNew, time.Minute)
+	r.ApplyGit(map[string]domain.GitState{"/r/main.go": domain.GitModified})
+	r.ApplyLogs(map[string]domain.LogActivity{"/r/pkg/app.log": {LinesPerSec: 3, Errors: 1}})
+	return r
+}
+
+func TestRoundTrip(t *testing.T) {
+	repo := testRepo()
+	sc := scene.Derive(repo, 80, 24, true)
+	var buf bytes.Buffer
+	if err := Write(&buf, Take(repo, &sc)); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, n := range snap.Nodes {
+		paths = append(paths, n.Path)
+	}
+	if got := strings.Join(paths, " "); got != ". main.go pkg pkg/app.log pkg/util.go" {
+		t.Fatalf("nodes = %s", got)
+	}
+	if snap.Layout == nil || len(snap.Layout.Slots) != len(sc.Slots) || snap.Layout.Width != sc.MapW {
+		t.Fatalf("layout = %+v, want the %d slots of a %d wide map", snap.Layout, len(sc.Slots), sc.MapW)
+	}
+
+	got, err := snap.Repo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Index) != len(repo.Index) || got.Stats != repo.Stats || got.Git != repo.Git {
+		t.Fatalf("repo = %d nodes, stats %+v, git %+v", len(got.Index), got.Stats, got.Git)
+	}
+	util := got.Index["/r/pkg/util.go"]
+	if util == nil || util.State != domain.StateNew || !util.IsStateActive() || util.Ext != ".go" {
+		t.Fatalf("util.go = %+v, want a new .go file still building", util)
+	}
+	if h := got.History["/r/pkg/util.go"]; len(h) != 1 || h[0].Kind != domain.Create {
+		t.Fatalf("history = %+v", h)
+	}
+	if got.Index["/r"].Git != domain.GitModified || got.Index["/r/pkg"].Git != domain.GitClean {
+		t.Fatalf("directories should carry the git state below them")
+	}
+	if got.Index["/r/pkg/app.log"].Log.Errors != 1 {
+		t.Fatalf("log activity lost")
+	}
+	// the same tree lays out the same way
+	again := scene.Derive(got, 80, 24, true)
+	if !reflect.DeepEqual(again.Slots, sc.Slots) {
+		t.Fatalf("loaded village lays out as %+v, want %+v", again.Slots, sc.Slots)
+	}
+}
+
+func TestReadRejects(t *testing.T) {
+	tests := []struct {
+		name, json, err string
+	}{
+		{"other version", `{"version": 2, "nodes": []}`, "version 2"},
+		{"no root", `{"version": 1, "root": "/r", "nodes": [{"path": "a.go"}]}`, "root directory"},
+		{"orphan", `{"version": 1, "root": "/r", "nodes": [{"path": ".", "dir": true}, {"path": "pkg/a.go"}]}`, "before its directory"},
+		{"bad state", `{"version": 1, "root": "/r", "nodes": [{"path": ".", "dir": true}, {"path": "a.go", "state": {"kind": "melting"}}]}`, "melting"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, err := Read(strings.NewReader(tt.json))
+			if err == nil {
+				_, err = snap.Repo()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
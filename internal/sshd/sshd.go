@@ -0,0 +1,102 @@
+// internal/sshd/sshd.go
+package sshd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	"github.com/muesli/termenv"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/live"
+	"example.com/village-watch/internal/ui"
+)
+
+// Options set where the server listens and who may connect
+type Options struct {
+	Addr           string
+	HostKey        string // private host key, generated when missing
+	AuthorizedKeys string // authorized_keys file; only its keys get in
+}
+
+// DefaultHostKey is the host key next to the user themes
+func DefaultHostKey() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "ssh_host_ed25519"
+	}
+	return filepath.Join(dir, "village-watch", "ssh_host_ed25519")
+}
+
+// DefaultAuthorizedKeys is the user's own authorized_keys file
+func DefaultAuthorizedKeys() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "authorized_keys")
+}
+
+// New returns an SSH server showing v in the TUI. Every session runs its own
+// ui.Model, with its own view, theme and terminal size, over the one shared
+// tree and watcher.
+func New(v *live.Village, cfg config.Config, opts Options) (*ssh.Server, error) {
+	if opts.AuthorizedKeys == "" {
+		return nil, errors.New("an authorized_keys file is required")
+	}
+	if _, err := os.Stat(opts.AuthorizedKeys); err != nil {
+		return nil, fmt.Errorf("authorized keys: %w", err)
+	}
+	srv, err := wish.NewServer(
+		wish.WithAddress(opts.Addr),
+		wish.WithHostKeyPath(opts.HostKey),
+		wish.WithAuthorizedKeys(opts.AuthorizedKeys),
+		wish.WithMiddleware(
+			// 256 colors at least, as themes use the xterm palette
+			bm.MiddlewareWithColorProfile(session(v, cfg), termenv.ANSI256),
+			activeterm.Middleware(),
+			logging.Middleware(),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: %w", err)
+	}
+	return srv, nil
+}
+
+// session starts the TUI for one connection
+func session(v *live.Village, cfg config.Config) bm.Handler {
+	return func(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
+		m, err := ui.NewSharedModel(v, cfg, bm.MakeRenderer(sess))
+		if err != nil {
+			wish.Fatalln(sess, err)
+			return nil, nil
+		}
+		return m, []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	}
+}
+
+// ListenAndServe serves the village until ctx is done, then gives open
+// sessions a moment to close
+func ListenAndServe(ctx context.Context, srv *ssh.Server) error {
+	go func() {
+		<-ctx.Done()
+		shutdown, done := context.WithTimeout(context.Background(), time.Second)
+		defer done()
+		_ = srv.Shutdown(shutdown)
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package sshd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+
+	"example.com/village-watch/internal/buildings"
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/live"
+)
+
+func TestSessionsShareOneVillage(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Git.Enabled, cfg.Logs.Enabled = false, false
+	cfg.Watch.DebounceMS = 20
+	v, err := live.Start(root, cfg, buildings.NewRenderer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	keys := t.TempDir()
+	allowed, stranger := signer(t), signer(t)
+	authorized := filepath.Join(keys, "authorized_keys")
+	if err := os.WriteFile(authorized, gossh.MarshalAuthorizedKey(allowed.PublicKey()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hostKey := filepath.Join(keys, "host", "ssh_host_ed25519")
+	srv, err := New(v, cfg, Options{HostKey: hostKey, AuthorizedKeys: authorized})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(hostKey); err != nil {
+		t.Fatalf("host key not generated: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	if _, err := dial(l.Addr().String(), stranger); err == nil {
+		t.Fatal("a key missing from authorized_keys got in")
+	}
+	// two terminals of different sizes follow the one watcher
+	a := attach(t, l.Addr().String(), allowed, 100, 30)
+	b := attach(t, l.Addr().String(), allowed, 60, 20)
+	a.wait(t, "New: 0")
+	b.wait(t, "New: 0")
+	if err := os.WriteFile(filepath.Join(root, "util.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a.wait(t, "New: 1")
+	b.wait(t, "New: 1")
+}
+
+func signer(t *testing.T) gossh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := gossh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func dial(addr string, s gossh.Signer) (*gossh.Client, error) {
+	return gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User:            "viewer",
+		Auth:            []gossh.AuthMethod{gossh.PublicKeys(s)},
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+}
+
+// screen collects what a session draws
+type screen struct {
+	mu  sync.Mutex
+	out bytes.Buffer
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.Write(p)
+}
+
+func (s *screen) wait(t *testing.T, text string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		found := strings.Contains(s.out.String(), text)
+		s.mu.Unlock()
+		if found {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %q", text)
+}
+
+// attach opens the TUI in a cols x rows terminal
+func attach(t *testing.T, addr string, s gossh.Signer, cols, rows int) *screen {
+	t.Helper()
+	client, err := dial(addr, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	sess, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.RequestPty("xterm-256color", rows, cols, gossh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	scr := &screen{}
+	sess.Stdout = scr
+	if err := sess.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	return scr
+}
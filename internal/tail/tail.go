@@ -0,0 +1,184 @@
+// internal/tail/tail.go
+package tail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"example.com/village-watch/internal/domain"
+)
+
+// Window is how long error and warning lines keep counting towards a burst
+const Window = 10 * time.Second
+
+// maxRead bounds the bytes read from one file per poll; a faster log is
+// caught up over the following polls
+const maxRead = 1 << 20
+
+// maxPartial bounds an unterminated line kept between polls; a longer line
+// is matched on its start
+const maxPartial = 64 << 10
+
+// Rules classify log lines; a nil pattern matches nothing
+type Rules struct {
+	Error *regexp.Regexp
+	Warn  *regexp.Regexp
+}
+
+// CompileRules compiles the error and warning patterns; empty ones are off
+func CompileRules(errorExpr, warnExpr string) (Rules, error) {
+	var r Rules
+	var err error
+	if errorExpr != "" {
+		if r.Error, err = regexp.Compile(errorExpr); err != nil {
+			return Rules{}, fmt.Errorf("error pattern: %w", err)
+		}
+	}
+	if warnExpr != "" {
+		if r.Warn, err = regexp.Compile(warnExpr); err != nil {
+			return Rules{}, fmt.Errorf("warn pattern: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// Tailer follows a set of log files from their end, surviving truncation
+// and rotation. It is not safe for concurrent use.
+type Tailer struct {
+	rules Rules
+	files map[string]*follower
+}
+
+type follower struct {
+	info    os.FileInfo // identifies the file being read, to notice rotation
+	offset  int64
+	partial []byte
+	long    bool // partial holds the start of an overlong line, whose rest is skipped
+	polled  time.Time
+	rate    float64 // lines per second, smoothed
+	bursts  []burst // error and warning lines within Window
+}
+
+type burst struct {
+	when          time.Time
+	errors, warns int
+}
+
+// New returns a Tailer following no files
+func New(rules Rules) *Tailer {
+	return &Tailer{rules: rules, files: map[string]*follower{}}
+}
+
+// Sync follows exactly paths: new ones from their current end, while
+// dropped ones are forgotten
+func (t *Tailer) Sync(paths []string) {
+	keep := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		keep[p] = true
+		if _, ok := t.files[p]; ok {
+			continue
+		}
+		f := &follower{}
+		if fi, err := os.Stat(p); err == nil {
+			f.info, f.offset = fi, fi.Size()
+		}
+		t.files[p] = f
+	}
+	for p := range t.files {
+		if !keep[p] {
+			delete(t.files, p)
+		}
+	}
+}
+
+// Poll reads what was appended to every followed file since the last poll
+// and returns their activity by path
+func (t *Tailer) Poll(now time.Time) map[string]domain.LogActivity {
+	out := make(map[string]domain.LogActivity, len(t.files))
+	for p, f := range t.files {
+		lines, errs, warns := t.read(p, f)
+		if !f.polled.IsZero() {
+			if dt := now.Sub(f.polled).Seconds(); dt > 0 {
+				f.rate = 0.5*f.rate + 0.5*float64(lines)/dt
+			}
+		}
+		f.polled = now
+		if errs > 0 || warns > 0 {
+			f.bursts = append(f.bursts, burst{when: now, errors: errs, warns: warns})
+		}
+		for len(f.bursts) > 0 && now.Sub(f.bursts[0].when) > Window {
+			f.bursts = f.bursts[1:]
+		}
+		a := domain.LogActivity{LinesPerSec: f.rate}
+		for _, b := range f.bursts {
+			a.Errors += b.errors
+			a.Warnings += b.warns
+		}
+		out[p] = a
+	}
+	return out
+}
+
+// read consumes new complete lines of the file at path, counting them and
+// the ones matching the rules. A file replaced since the last read (rotation)
+// or shorter than what was read (truncation) is read from its start.
+func (t *Tailer) read(path string, f *follower) (lines, errs, warns int) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		// gone for now; a file created later under the name is new
+		f.info, f.offset, f.partial, f.long = nil, 0, nil, false
+		return 0, 0, 0
+	}
+	if f.info == nil || !os.SameFile(f.info, fi) || fi.Size() < f.offset {
+		f.offset, f.partial, f.long = 0, nil, false
+	}
+	f.info = fi
+	if fi.Size() == f.offset {
+		return 0, 0, 0
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, 0
+	}
+	defer file.Close()
+	buf := make([]byte, min(fi.Size()-f.offset, maxRead))
+	n, err := file.ReadAt(buf, f.offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, 0, 0
+	}
+	f.offset += int64(n)
+	chunk := buf[:n]
+	if f.long {
+		// skip what remains of the overlong line, up to its newline
+		i := bytes.IndexByte(chunk, '\n')
+		if i < 0 {
+			return 0, 0, 0
+		}
+		chunk, f.long = chunk[i:], false
+	}
+	data := append(f.partial, chunk...)
+	end := bytes.LastIndexByte(data, '\n')
+	rest := data[end+1:]
+	if len(rest) > maxPartial {
+		rest, f.long = rest[:maxPartial], true
+	}
+	f.partial = append([]byte(nil), rest...)
+	if end < 0 {
+		return 0, 0, 0
+	}
+	for _, line := range bytes.Split(data[:end], []byte{'\n'}) {
+		lines++
+		switch {
+		case t.rules.Error != nil && t.rules.Error.Match(line):
+			errs++
+		case t.rules.Warn != nil && t.rules.Warn.Match(line):
+			warns++
+		}
+	}
+	return lines, errs, warns
+}
@@ -0,0 +1,113 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendLines := func(s string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rules, err := CompileRules(`ERROR`, `WARN`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendLines("old line\nold ERROR\n")
+
+	tl := New(rules)
+	tl.Sync([]string{path})
+	now := time.Now()
+	if a := tl.Poll(now)[path]; a.LinesPerSec != 0 || a.Errors != 0 {
+		t.Fatalf("existing content counted: %+v", a)
+	}
+
+	steps := []struct {
+		name   string
+		change func()
+		errors int
+		warns  int
+	}{
+		{"append", func() { appendLines("ok\nWARN disk\nERROR boom\npart") }, 1, 1},
+		{"finish partial line", func() { appendLines("ial ERROR\n") }, 2, 1},
+		{"truncate", func() { os.WriteFile(path, []byte("ERROR again\n"), 0o644) }, 3, 1},
+		{"rotate", func() {
+			os.Rename(path, path+".1")
+			os.WriteFile(path, []byte("fresh\nWARN new file\n"), 0o644)
+		}, 3, 2},
+	}
+	for _, s := range steps {
+		s.change()
+		now = now.Add(time.Second)
+		a := tl.Poll(now)[path]
+		if a.Errors != s.errors || a.Warnings != s.warns || a.LinesPerSec <= 0 {
+			t.Fatalf("%s: %+v, want %d errors, %d warnings and some lines", s.name, a, s.errors, s.warns)
+		}
+	}
+
+	// Bursts leave the window and an idle log cools down
+	now = now.Add(Window + time.Second)
+	if a := tl.Poll(now)[path]; a.Errors != 0 || a.Warnings != 0 {
+		t.Fatalf("after the window: %+v", a)
+	}
+	tl.Sync(nil)
+	if len(tl.Poll(now)) != 0 {
+		t.Fatalf("dropped file still followed")
+	}
+}
+
+func TestOverlongLineCountsOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := CompileRules(`ERROR`, `WARN`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl := New(rules)
+	tl.Sync([]string{path})
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// a line longer than what is kept between polls, written over three
+	// polls: it starts with a warning and ends in an error past the kept start
+	long := "WARN " + strings.Repeat("x", maxPartial) + " WARN"
+	var lines, errs, warns int
+	for _, chunk := range []string{long, strings.Repeat("y", maxPartial), " ERROR\nok\n"} {
+		if _, err := f.WriteString(chunk); err != nil {
+			t.Fatal(err)
+		}
+		l, e, w := tl.read(path, tl.files[path])
+		lines, errs, warns = lines+l, errs+e, warns+w
+	}
+	if lines != 2 || errs != 0 || warns != 1 {
+		t.Fatalf("read %d lines, %d errors, %d warnings; want the overlong line once, as a warning, then ok", lines, errs, warns)
+	}
+}
+
+func TestCompileRulesRejectsBadPatterns(t *testing.T) {
+	if _, err := CompileRules(`(`, ""); err == nil {
+		t.Fatal("want error")
+	}
+	r, err := CompileRules("", "")
+	if err != nil || r.Error != nil || r.Warn != nil {
+		t.Fatalf("empty patterns: %+v, %v", r, err)
+	}
+}
@@ -0,0 +1,231 @@
+// internal/timelapse/timelapse.go
+package timelapse
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/git"
+	"example.com/village-watch/internal/ignore"
+)
+
+// ErrNoHistory is returned when root has no commits to replay
+var ErrNoHistory = errors.New("no commits to replay")
+
+// Frame is the village as of one commit
+type Frame struct {
+	Index  int // position in History
+	Commit git.Commit
+	Repo   *domain.RepoState
+	Events []domain.FsEvent // changes since the previous commit, or undone from the next one
+	Back   bool             // stepped back to from the next commit; see LoadBack
+}
+
+// History replays the first-parent commits of the repository holding root
+type History struct {
+	root    string
+	matcher *ignore.Matcher
+	Commits []git.Commit
+
+	// the last tree read, so playing forward lists one tree per commit
+	lastIdx  int
+	lastTree map[string]git.TreeEntry
+}
+
+// Open reads the commit list of root
+func Open(root string, cfg config.Config) (*History, error) {
+	commits, err := git.Log(root)
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	if len(commits) == 0 {
+		return nil, ErrNoHistory
+	}
+	return &History{root: root, matcher: ignore.New(root, cfg.Watch.Ignore), Commits: commits, lastIdx: -1}, nil
+}
+
+// Len is the number of commits
+func (h *History) Len() int { return len(h.Commits) }
+
+// tree returns the files of commit i by slash path, leaving out ignored ones
+func (h *History) tree(i int) (map[string]git.TreeEntry, error) {
+	if i < 0 {
+		return map[string]git.TreeEntry{}, nil
+	}
+	if i == h.lastIdx {
+		return h.lastTree, nil
+	}
+	entries, err := git.Tree(h.root, h.Commits[i].Hash)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]git.TreeEntry, len(entries))
+	for _, e := range entries {
+		if !h.matcher.Match(filepath.Join(h.root, filepath.FromSlash(e.Path)), false) {
+			files[e.Path] = e
+		}
+	}
+	return files, nil
+}
+
+// Load builds the frame of commit i. Files the commit deletes stay in the
+// tree so that their demolition can play; see Frame.Continue.
+func (h *History) Load(i int) (Frame, error) {
+	return h.load(i, i-1)
+}
+
+// LoadBack builds the frame of commit i when stepping back from commit i+1.
+// Its events undo that commit: files it added are demolished, files it
+// changed are modified again and files it deleted are rebuilt.
+func (h *History) LoadBack(i int) (Frame, error) {
+	f, err := h.load(i, i+1)
+	f.Back = true
+	return f, err
+}
+
+// load builds the frame of commit i with the events that lead to it from
+// commit from
+func (h *History) load(i, from int) (Frame, error) {
+	if i < 0 || i >= len(h.Commits) || from >= len(h.Commits) {
+		return Frame{}, fmt.Errorf("commit %d out of range", i)
+	}
+	before, err := h.tree(from)
+	if err != nil {
+		return Frame{}, err
+	}
+	after, err := h.tree(i)
+	if err != nil {
+		return Frame{}, err
+	}
+	h.lastIdx, h.lastTree = i, after
+
+	c := h.Commits[i]
+	repo := domain.NewRepo(h.root)
+	repo.Root = &domain.FileNode{Path: h.root, Name: filepath.Base(h.root), IsDir: true, ModTime: c.When}
+	repo.Upsert(repo.Root)
+	var events []domain.FsEvent
+	for p, e := range after {
+		n := h.add(repo, p, e.Size, c.When)
+		if old, existed := before[p]; !existed {
+			events = append(events, domain.FsEvent{Path: n.Path, Kind: domain.Create, When: c.When})
+		} else if old.Blob != e.Blob {
+			events = append(events, domain.FsEvent{Path: n.Path, Kind: domain.Write, When: c.When})
+		}
+	}
+	for p, e := range before {
+		if _, kept := after[p]; !kept {
+			n := h.add(repo, p, e.Size, c.When)
+			events = append(events, domain.FsEvent{Path: n.Path, Kind: domain.Remove, When: c.When})
+		}
+	}
+	sort.Slice(events, func(a, b int) bool { return events[a].Path < events[b].Path })
+	repo.LastRefresh = time.Now()
+	return Frame{Index: i, Commit: c, Repo: repo, Events: events}, nil
+}
+
+// Continue takes over stats, event history and modification times from the
+// village shown before, prev (nil at the start), and starts the animations:
+// construction for added files, activity for changed ones and demolition
+// for deleted files and districts left empty. domain.RepoState.PruneDemolished
+// clears the ruins once anim has passed. A frame stepped back to counts
+// nothing and drops the events prev recorded instead, so the caller restores
+// the counters it had.
+func (f Frame) Continue(prev *domain.RepoState, anim time.Duration) {
+	repo := f.Repo
+	if prev != nil {
+		repo.Stats = prev.Stats
+		repo.History = prev.History
+		repo.PruneHistory()
+		for p, n := range repo.Index {
+			if pn := prev.Index[p]; pn != nil && !n.IsDir {
+				n.ModTime = pn.ModTime
+			}
+		}
+	}
+	for _, e := range f.Events {
+		n := repo.Index[e.Path]
+		n.ModTime = e.When
+		switch e.Kind {
+		case domain.Create:
+			n.SetState(domain.StateNew, anim)
+		case domain.Write:
+			n.SetState(domain.StateModified, anim)
+		case domain.Remove:
+			n.SetState(domain.StateDeleted, anim)
+		}
+		if f.Back {
+			// each path the next commit touched has its event last
+			if h := repo.History[e.Path]; len(h) > 1 {
+				repo.History[e.Path] = h[:len(h)-1]
+			} else {
+				delete(repo.History, e.Path)
+			}
+			continue
+		}
+		switch e.Kind {
+		case domain.Create:
+			repo.Stats.NewFiles++
+		case domain.Write:
+			repo.Stats.Modified++
+		case domain.Remove:
+			repo.Stats.Deleted++
+		}
+		repo.RecordEvent(e)
+	}
+	for _, ch := range repo.Root.Children {
+		demolishEmptyDirs(ch, anim)
+	}
+}
+
+// add links a file node at slash path p, creating its districts as needed
+func (h *History) add(repo *domain.RepoState, p string, size int64, when time.Time) *domain.FileNode {
+	dir := repo.Root
+	if d := path.Dir(p); d != "." {
+		dir = h.district(repo, d)
+	}
+	full := filepath.Join(h.root, filepath.FromSlash(p))
+	n := &domain.FileNode{Path: full, Name: path.Base(p), Ext: domain.Ext(p), Size: size, ModTime: when}
+	dir.Children = append(dir.Children, n)
+	repo.Upsert(n)
+	return n
+}
+
+func (h *History) district(repo *domain.RepoState, d string) *domain.FileNode {
+	full := filepath.Join(h.root, filepath.FromSlash(d))
+	if n, ok := repo.Index[full]; ok {
+		return n
+	}
+	parent := repo.Root
+	if pd := path.Dir(d); pd != "." {
+		parent = h.district(repo, pd)
+	}
+	n := &domain.FileNode{Path: full, Name: path.Base(d), IsDir: true}
+	parent.Children = append(parent.Children, n)
+	repo.Upsert(n)
+	return n
+}
+
+// demolishEmptyDirs marks districts holding nothing but demolished files as
+// demolished too, so they go once the animation ends. It reports whether n
+// is being demolished.
+func demolishEmptyDirs(n *domain.FileNode, anim time.Duration) bool {
+	if !n.IsDir {
+		return n.State == domain.StateDeleted
+	}
+	gone := len(n.Children) > 0
+	for _, ch := range n.Children {
+		if !demolishEmptyDirs(ch, anim) {
+			gone = false
+		}
+	}
+	if gone {
+		n.SetState(domain.StateDeleted, anim)
+	}
+	return gone
+}
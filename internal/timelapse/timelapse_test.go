@@ -0,0 +1,97 @@
+package timelapse
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+)
+
+func TestReplay(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Ada", "-c", "user.email=a@a", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("main.go", "package main\n")
+	write("old/notes.md", "hi\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "start")
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("pkg/lib.go", "package pkg\n")
+	git("rm", "-q", "old/notes.md")
+	git("add", ".")
+	git("commit", "-q", "-m", "grow")
+
+	h, err := Open(dir, config.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 2 || h.Commits[1].Subject != "grow" || h.Commits[1].Author != "Ada" {
+		t.Fatalf("commits = %+v", h.Commits)
+	}
+	first, err := h.Load(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Continue(nil, time.Minute)
+	second, err := h.Load(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Continue(first.Repo, time.Minute)
+
+	repo := second.Repo
+	states := map[string]domain.FileState{
+		"main.go":      domain.StateModified,
+		"pkg/lib.go":   domain.StateNew,
+		"old/notes.md": domain.StateDeleted,
+		"old":          domain.StateDeleted,
+	}
+	for rel, want := range states {
+		n := repo.Index[filepath.Join(dir, rel)]
+		if n == nil || n.State != want {
+			t.Fatalf("%s: node %+v, want state %v", rel, n, want)
+		}
+	}
+	if repo.Stats != (domain.ActivityStats{NewFiles: 3, Modified: 1, Deleted: 1}) {
+		t.Fatalf("stats = %+v", repo.Stats)
+	}
+	if len(repo.History[filepath.Join(dir, "main.go")]) != 2 {
+		t.Fatalf("history of main.go = %v", repo.History[filepath.Join(dir, "main.go")])
+	}
+
+	// Once the demolition is over the ruins go
+	for _, n := range repo.Index {
+		n.StateExpiry = time.Now().Add(-time.Second)
+	}
+	repo.PruneDemolished()
+	if _, ok := repo.Index[filepath.Join(dir, "old")]; ok {
+		t.Fatalf("empty district should be pruned")
+	}
+	if len(repo.Root.Children) != 2 {
+		t.Fatalf("root children = %d, want main.go and pkg", len(repo.Root.Children))
+	}
+}
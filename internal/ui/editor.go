@@ -0,0 +1,103 @@
+// internal/ui/editor.go
+package ui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"example.com/village-watch/internal/git"
+)
+
+// editorDoneMsg arrives when the editor opened on path exits
+type editorDoneMsg struct {
+	path string
+	err  error
+}
+
+// editorCommand builds the command that opens path at line. A template is
+// split into words first, then {path} and {line} are replaced in each word,
+// so paths with spaces stay one argument. Without a template $VISUAL or
+// $EDITOR is used, with "+line" when the line is past the first.
+func editorCommand(template, path string, line int, getenv func(string) string) (*exec.Cmd, error) {
+	if strings.TrimSpace(template) == "" {
+		editor := getenv("VISUAL")
+		if editor == "" {
+			editor = getenv("EDITOR")
+		}
+		if editor == "" {
+			return nil, errors.New("no editor: set $VISUAL, $EDITOR or editor in village.yml")
+		}
+		args := strings.Fields(editor)
+		if line > 1 {
+			args = append(args, "+"+strconv.Itoa(line))
+		}
+		args = append(args, path)
+		return exec.Command(args[0], args[1:]...), nil
+	}
+	args := strings.Fields(template)
+	hasPath := false
+	for i, a := range args {
+		hasPath = hasPath || strings.Contains(a, "{path}")
+		a = strings.ReplaceAll(a, "{path}", path)
+		args[i] = strings.ReplaceAll(a, "{line}", strconv.Itoa(max(1, line)))
+	}
+	if !hasPath {
+		args = append(args, path)
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// openEditor suspends the village while the editor runs on the selected file
+func (m *Model) openEditor() tea.Cmd {
+	switch {
+	case m.village != nil:
+		// the editor would run on the machine serving the village
+		m.notice = "the editor only opens in a local village"
+		return nil
+	case m.lapse != nil:
+		// the tree is a past commit's; editing would restat the working tree into it
+		m.notice = "the editor does not open in a time-lapse"
+		return nil
+	case m.still:
+		// the snapshot's files may not exist here, or not as recorded
+		m.notice = "the editor does not open on a snapshot"
+		return nil
+	case m.replay != nil:
+		// same for a recording, whose tree the replay must not diverge from
+		m.notice = "the editor does not open in a replay"
+		return nil
+	}
+	n := m.repo.Index[m.selected]
+	if n == nil || n.IsDir {
+		m.notice = "select a file first: click it or press tab"
+		return nil
+	}
+	// open at the first change since the last commit, if git knows of one
+	line := 1
+	if m.cfg.Git.Enabled {
+		if l, err := git.FirstChangedLine(n.Path); err == nil && l > 0 {
+			line = l
+		}
+	}
+	cmd, err := editorCommand(m.cfg.Editor, n.Path, line, os.Getenv)
+	if err != nil {
+		m.notice = err.Error()
+		return nil
+	}
+	path := n.Path
+	return tea.ExecProcess(cmd, func(err error) tea.Msg { return editorDoneMsg{path: path, err: err} })
+}
+
+// editorDone rescans the edited file so changes show without waiting for the
+// watcher
+func (m *Model) editorDone(msg editorDoneMsg) {
+	if msg.err != nil {
+		m.notice = "editor: " + msg.err.Error()
+	}
+	m.restat([]string{msg.path})
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	tests := []struct {
+		name     string
+		template string
+		line     int
+		env      map[string]string
+		want     []string
+		wantErr  bool
+	}{
+		{"visual wins", "", 1, map[string]string{"VISUAL": "code -w", "EDITOR": "vi"}, []string{"code", "-w", "/r/a b.go"}, false},
+		{"editor with line", "", 12, map[string]string{"EDITOR": "vim"}, []string{"vim", "+12", "/r/a b.go"}, false},
+		{"template", "code -g {path}:{line}", 7, nil, []string{"code", "-g", "/r/a b.go:7"}, false},
+		{"template without path", "subl --wait", 1, nil, []string{"subl", "--wait", "/r/a b.go"}, false},
+		{"nothing set", "", 1, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := editorCommand(tt.template, "/r/a b.go", tt.line, env(tt.env))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", cmd.Args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cmd.Args, tt.want) {
+				t.Fatalf("args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// internal/ui/filter.go
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/filter"
+)
+
+// filterKey edits the filter prompt. Enter applies a valid expression,
+// turns the filter on and saves it; an invalid one stays in the prompt.
+func (m Model) filterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		if m.stop != nil {
+			_ = m.stop()
+		}
+		return m, tea.Quit
+	case tea.KeyEnter:
+		f, err := filter.Parse(m.filterInput)
+		if err != nil {
+			m.filterErr = err.Error()
+			break
+		}
+		m.filter, m.filterEditing = f, false
+		m.cfg.Filter.Enabled = true
+		m.saveFilter()
+	case tea.KeyEsc:
+		m.filterEditing = false
+	default:
+		if s, ok := editText(m.filterInput, msg); ok {
+			m.filterInput, m.filterErr = s, ""
+		}
+	}
+	m.scene.Prompt = m.prompt()
+	return m, nil
+}
+
+// saveFilter stores the filter and whether it is on in village.yml. Sessions
+// of a shared village, still villages and replays keep theirs to themselves.
+func (m *Model) saveFilter() {
+	m.cfg.Filter = m.filter.Config(m.cfg.Filter.Enabled)
+	if m.village != nil || m.still || m.replay != nil {
+		m.scene.Prompt = m.prompt()
+		return
+	}
+	if err := config.SaveFilter(m.root, m.cfg.Filter); err != nil {
+		m.notice = "filter not saved: " + err.Error()
+	}
+	m.scene.Prompt = m.prompt()
+}
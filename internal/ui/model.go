@@ -2,26 +2,46 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"example.com/village-watch/internal/buildings"
 	"example.com/village-watch/internal/config"
 	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/filter"
+	"example.com/village-watch/internal/git"
+	"example.com/village-watch/internal/layout"
+	"example.com/village-watch/internal/live"
+	"example.com/village-watch/internal/recording"
 	"example.com/village-watch/internal/render"
 	"example.com/village-watch/internal/scan"
 	"example.com/village-watch/internal/scene"
+	"example.com/village-watch/internal/search"
+	"example.com/village-watch/internal/tail"
 	"example.com/village-watch/internal/watch"
 )
 
 type tickMsg time.Time
 type eventsMsg watch.EventOut
+type reconcileMsg struct{ repo *domain.RepoState }
+type logsMsg map[string]domain.LogActivity
+type gitMsg struct {
+	states map[string]domain.GitState
+	err    error
+}
 
 type Model struct {
 	root           string
 	cfg            config.Config
 	width, height  int
 	repo           *domain.RepoState
+	renderer       *buildings.Renderer
+	themes         *render.ThemeSet
 	scene          scene.Scene
 	paused         bool
 	out            chan watch.EventOut
@@ -31,24 +51,95 @@ type Model struct {
 	fps            float64
 	frameCount     int
 	showHelp       bool
-	filterActive   bool
+	filter         filter.Filter // applied while cfg.Filter.Enabled
+	filterEditing  bool          // the filter prompt has focus
+	filterInput    string
+	filterErr      string // why the last expression was rejected
+	notice         string // one-off message in the prompt line, e.g. a save error
 	labelsVisible  bool
+	miniMapVisible bool
+	viewX, viewY   int  // viewport in map cells, once the user has panned
+	viewSet        bool // false keeps the view centered on the map
+	zoom           int
+	dragging       bool
+	dragMoved      bool // the press became a drag rather than a click
+	dragX, dragY   int  // last mouse position while dragging
+	inspected      *layout.Slot // slot shown in the inspector panel
+	selected       string       // path under the selection cursor
+	searching      bool         // the search prompt has focus
+	query          string
+	matches        []string // Index paths matching query, best first
+	matchIdx       int
+	gitStates      map[string]domain.GitState // last git status read, reapplied as the tree changes
+	lapse          *timelapseState            // set when replaying history instead of watching
+	tailer         *tail.Tailer               // follows lantern logs; nil when disabled
+	logActivity    map[string]domain.LogActivity
+	village        *live.Village       // shared with other sessions, which then owns the tree; nil watches on its own
+	still          bool                // a recorded tree that nothing updates; it is not rescanned or saved to
+	bounds         *scene.MapBounds    // pins the map size, e.g. to a snapshot's; nil follows render.map
+	replay         *replayState        // set when playing a recorded session instead of watching
+	recorder       *recording.Recorder // writes the watcher batches to a session file
+	stater         *scan.Stater        // restats watched paths, keeping the ignore rules between batches
+	layouts        *scene.LayoutCache  // the last layout, reused while the tree and view size stay put
 }
 
 func NewModel(root string, cfg config.Config) (Model, error) {
-	repo, err := scan.BuildTree(root, cfg)
+	m, err := newModel(root, cfg)
 	if err != nil {
 		return Model{}, err
 	}
-	out, stop, err := watch.Start(root, cfg)
-	if err != nil {
+	if m.repo, err = scan.BuildTree(root, cfg); err != nil {
+		return Model{}, err
+	}
+	m.repo.Ruins = domain.DemolitionTime
+	if m.out, m.stop, err = watch.Start(root, cfg); err != nil {
 		return Model{}, err
 	}
-	m := Model{root: root, cfg: cfg, repo: repo, out: out, stop: stop, labelsVisible: false}
+	if cfg.Logs.Enabled {
+		rules, err := tail.CompileRules(cfg.Logs.Error, cfg.Logs.Warn)
+		if err != nil {
+			return Model{}, fmt.Errorf("logs: %w", err)
+		}
+		m.tailer = tail.New(rules)
+	}
 	return m, nil
 }
 
-func (m Model) Init() tea.Cmd { return tea.Batch(tick(m.cfg.FPS), waitEvents(m.out)) }
+// newModel loads what live and time-lapse villages share: designs, themes
+// and the filter
+func newModel(root string, cfg config.Config) (Model, error) {
+	renderer, err := buildings.NewRendererFromConfig(root, cfg)
+	if err != nil {
+		return Model{}, fmt.Errorf("config: %w", err)
+	}
+	themeDir := cfg.ThemesDir
+	if themeDir == "" {
+		themeDir = render.DefaultThemeDir()
+	}
+	themes, err := render.LoadThemes(themeDir)
+	if err != nil {
+		return Model{}, fmt.Errorf("themes: %w", err)
+	}
+	flt, err := filter.FromConfig(cfg.Filter)
+	if err != nil {
+		return Model{}, fmt.Errorf("filter: %w", err)
+	}
+	return Model{
+		root: root, cfg: cfg, renderer: renderer, themes: themes, filter: flt, labelsVisible: false,
+		stater: scan.NewStater(root, cfg), layouts: &scene.LayoutCache{},
+	}, nil
+}
+
+func (m Model) Init() tea.Cmd {
+	if m.lapse != nil {
+		return tea.Batch(tick(m.cfg.FPS), m.loadCommit(0))
+	}
+	if m.out == nil {
+		// shared, still and replayed villages follow nothing themselves
+		return tick(m.cfg.FPS)
+	}
+	return tea.Batch(tick(m.cfg.FPS), waitEvents(m.out), reconcile(m.root, m.cfg), readGit(m.root, m.cfg, 0), m.pollLogs(0))
+}
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -56,6 +147,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width, m.height = msg.Width, msg.Height
 		return m, nil
 	case tea.KeyMsg:
+		m.notice = ""
+		if m.searching {
+			return m.searchKey(msg)
+		}
+		if m.filterEditing {
+			return m.filterKey(msg)
+		}
+		if m.lapse != nil {
+			if lm, cmd, ok := m.timelapseKey(msg); ok {
+				return lm, cmd
+			}
+		}
+		if m.replay != nil {
+			if rm, cmd, ok := m.replayKey(msg); ok {
+				return rm, cmd
+			}
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			if m.stop != nil {
@@ -64,29 +172,94 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "p":
 			m.paused = !m.paused
-		case "h":
+			if m.replay != nil {
+				// the recording's clock stands still while paused
+				m.replay.last = time.Time{}
+			}
+		case "?":
 			m.showHelp = !m.showHelp
 		case "f":
-			m.filterActive = !m.filterActive
-		case "l":
+			m.cfg.Filter.Enabled = !m.cfg.Filter.Enabled
+			m.saveFilter()
+		case "F":
+			m.filterEditing, m.filterInput, m.filterErr = true, m.filter.String(), ""
+			m.scene.Prompt = m.prompt()
+		case "L":
 			m.labelsVisible = !m.labelsVisible
+		case "m":
+			m.miniMapVisible = !m.miniMapVisible
+			m.scene.MiniMapVisible = m.miniMapVisible
+		case "left", "h", "a":
+			m.pan(-panStepX, 0)
+		case "right", "l", "d":
+			m.pan(panStepX, 0)
+		case "up", "k", "w":
+			m.pan(0, -panStepY)
+		case "down", "j", "s":
+			m.pan(0, panStepY)
+		case "+", "=":
+			m.setZoom(m.zoom - 1)
+		case "-":
+			m.setZoom(m.zoom + 1)
+		case "0":
+			m.setZoom(0)
 		case "t":
-			// Cycle through themes
-			themes := []string{"forest", "seaside", "desert", "contrast"}
-			for i, theme := range themes {
-				if m.cfg.Theme == theme {
-					m.cfg.Theme = themes[(i+1)%len(themes)]
-					break
-				}
-			}
+			// Cycle through all discovered themes
+			m.cfg.Theme = m.themes.Next(m.cfg.Theme)
 		case "r":
 			// Force refresh
-			repo, _ := scan.BuildTree(m.root, m.cfg)
-			m.preserveAnimationStates(repo)
-			m.repo = repo
-		case "escape":
+			if m.village != nil {
+				return m, m.rescan()
+			}
+			if m.still || m.replay != nil {
+				break
+			}
+			if scanned, err := scan.BuildTree(m.root, m.cfg); err == nil {
+				m.reconcileWith(scanned)
+			}
+		case "/":
+			m.searching, m.query, m.matches = true, "", nil
+			m.updateSearch()
+		case "tab":
+			m.selectNext(1)
+		case "shift+tab":
+			m.selectNext(-1)
+		case "e", "enter":
+			cmd := m.openEditor()
+			m.scene.Prompt = m.prompt()
+			return m, cmd
+		case "n":
+			m.jump(m.matchIdx + 1)
+		case "N":
+			m.jump(m.matchIdx - 1)
+		case "esc", "escape":
 			m.showHelp = false
-			m.filterActive = false
+			m.inspected = nil
+			m.query, m.matches = "", nil
+			m.updateSearch()
+		}
+		return m, nil
+	case tea.MouseMsg:
+		switch {
+		case msg.Button == tea.MouseButtonWheelUp:
+			m.setZoom(m.zoom - 1)
+		case msg.Button == tea.MouseButtonWheelDown:
+			m.setZoom(m.zoom + 1)
+		case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+			m.dragging, m.dragMoved, m.dragX, m.dragY = true, false, msg.X, msg.Y
+		case msg.Action == tea.MouseActionMotion && m.dragging:
+			// Dragging moves the map with the pointer
+			if msg.X == m.dragX && msg.Y == m.dragY {
+				break
+			}
+			kx, ky := scene.ZoomScale(m.zoom)
+			m.panCells((m.dragX-msg.X)*kx, (m.dragY-msg.Y)*ky)
+			m.dragX, m.dragY, m.dragMoved = msg.X, msg.Y, true
+		case msg.Action == tea.MouseActionRelease:
+			if m.dragging && !m.dragMoved {
+				m.click(msg.X, msg.Y)
+			}
+			m.dragging = false
 		}
 		return m, nil
 	case tickMsg:
@@ -101,55 +274,286 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.lastTick = now
 		m.frameCount++
 		
+		var load tea.Cmd
 		if !m.paused {
-			m.repo.UpdateStates()
-			s := scene.DeriveWithFPS(m.repo, max(10, m.width), max(5, m.height-2), m.cfg.Render.Unicode, m.fps)
-			s.LabelsVisible = m.labelsVisible
-			if s.LabelsVisible {
-				s.DrawLabels(m.repo)
-				vx, vy := s.ViewportX, s.ViewportY
-				w, h := max(10, m.width), max(5, m.height-2)
-				s.Canvas = scene.ExtractViewportForUI(s.VirtualMap, w, h, vx, vy)
-			}
-			m.scene = s
+			m.frame(func(repo *domain.RepoState) {
+				load = m.advance(now)
+				m.replayTo(now)
+				bounds := scene.BoundsFromConfig(m.cfg.Render.Map)
+				if m.bounds != nil {
+					bounds = *m.bounds
+				}
+				opts := scene.Options{
+					Unicode: m.cfg.Render.Unicode, FPS: m.fps, Renderer: m.renderer, Bounds: &bounds, Zoom: m.zoom,
+					Layout: m.layouts,
+				}
+				if m.cfg.Filter.Enabled {
+					opts.Filter = &m.filter
+				}
+				if m.viewSet {
+					opts.Viewport = &scene.Viewport{X: m.viewX, Y: m.viewY}
+				}
+				s := scene.DeriveWithOptions(repo, max(10, m.width), max(5, m.height-2), opts)
+				// Keep the clamped viewport so panning never runs off the map
+				m.viewX, m.viewY = s.ViewportX, s.ViewportY
+				s.LabelsVisible = m.labelsVisible
+				s.MiniMapVisible = m.miniMapVisible
+				s.Status = m.lapse.status() + m.replay.status() + s.Status
+				s.Prompt = m.prompt()
+				if len(m.matches) > 0 {
+					s.Highlight(m.matches)
+				}
+				if m.selected != "" {
+					s.Select(m.selected)
+				}
+				if s.LabelsVisible {
+					s.DrawLabels(repo)
+				}
+				m.scene = s
+			})
 		}
-		return m, tick(m.cfg.FPS)
+		return m, tea.Batch(tick(m.cfg.FPS), load)
+	case frameMsg:
+		m.showFrame(msg)
+		return m, nil
 	case eventsMsg:
-		// Process events and set animation states
-		for _, e := range msg.Events {
-			switch e.Kind {
-			case domain.Create:
-				m.repo.Stats.NewFiles++
-				// Mark new files with construction animation for 2 seconds
-				m.repo.SetFileState(e.Path, domain.StateNew, 2*time.Second)
-			case domain.Write:
-				m.repo.Stats.Modified++
-				// Mark modified files with chimney puff for 1 second
-				m.repo.SetFileState(e.Path, domain.StateModified, 1*time.Second)
-			case domain.Remove, domain.Rename:
-				m.repo.Stats.Deleted++
-				// Mark deleted files with demolition for 1 second
-				m.repo.SetFileState(e.Path, domain.StateDeleted, 1*time.Second)
-			}
-		}
-		// Rebuild the tree to reflect actual filesystem state
-		repo, _ := scan.BuildTree(m.root, m.cfg)
-		// Preserve animation states from old repo
-		m.preserveAnimationStates(repo)
-		m.repo = repo
+		// Patch the tree in place, then set animation states on the result
+		m.observe(watch.EventOut(msg))
+		// New nodes pick up their state until the next git read
+		m.annotate()
 		return m, waitEvents(m.out)
+	case editorDoneMsg:
+		m.editorDone(msg)
+		m.scene.Prompt = m.prompt()
+		return m, nil
+	case reconcileMsg:
+		// Background rescan catches anything the watcher missed
+		if msg.repo != nil {
+			m.reconcileWith(msg.repo)
+		}
+		return m, reconcile(m.root, m.cfg)
+	case gitMsg:
+		if errors.Is(msg.err, git.ErrNotRepo) {
+			return m, nil
+		}
+		// On other errors the last known states stay up
+		if msg.err == nil {
+			m.gitStates = msg.states
+			m.repo.ApplyGit(m.gitStates)
+		}
+		// git status walks the whole tree, so poll no faster than 4 times a second
+		return m, readGit(m.root, m.cfg, time.Duration(max(250, m.cfg.Git.RefreshMS))*time.Millisecond)
+	case logsMsg:
+		m.logActivity = msg
+		m.repo.ApplyLogs(m.logActivity)
+		return m, m.pollLogs(time.Duration(max(50, m.cfg.Logs.PollMS)) * time.Millisecond)
 	}
 	return m, nil
 }
 
 func (m Model) View() string {
-	theme := render.ThemeByName(m.cfg.Theme)
+	theme := m.themes.Get(m.cfg.Theme)
 	
 	if m.showHelp {
-		return render.ViewWithHelp(m.scene, theme, m.width, m.height, m.paused, m.filterActive, m.cfg.Theme)
+		return render.ViewWithHelp(m.scene, theme, m.width, m.height, m.paused, m.cfg.Filter.Enabled, m.cfg.Theme)
 	}
 	
-	return render.ViewWithStatus(m.scene, theme, m.width, m.height, m.paused, m.filterActive, m.cfg.Theme)
+	return render.ViewWithInspector(m.scene, theme, m.width, m.height, m.paused, m.cfg.Filter.Enabled, m.cfg.Theme, m.inspection())
+}
+
+// click opens the inspector on the slot under a view position, or closes it
+// when the click hits open ground. Clicks on the panel itself are ignored.
+func (m *Model) click(x, y int) {
+	if m.inspected != nil && x < render.InspectorWidth(m.scene.W) {
+		return
+	}
+	if slot, ok := m.scene.SlotAt(x, y); ok {
+		m.inspected = &slot
+		m.selected = slot.Path
+		m.scene.Select(m.selected)
+	} else {
+		m.inspected = nil
+	}
+}
+
+// inspection gathers what the inspector panel shows about the clicked slot
+// from the current tree, so it stays live while files change. It copies what
+// it needs, as a shared tree changes under the panel.
+func (m Model) inspection() *render.Inspection {
+	if m.inspected == nil {
+		return nil
+	}
+	slot := *m.inspected
+	in := &render.Inspection{Path: slot.Path, Hidden: slot.Hidden}
+	if rel, err := filepath.Rel(m.root, slot.Path); err == nil {
+		in.Path = filepath.ToSlash(rel)
+	}
+	m.withRepo(func(repo *domain.RepoState) {
+		in.History = slices.Clone(repo.History[slot.Path])
+		n := repo.Index[slot.Path]
+		if n == nil {
+			return
+		}
+		node := *n
+		in.Node = &node
+		in.Archetype = m.renderer.Archetype(repo, n)
+		if slot.Kind != layout.SlotHamlet {
+			in.Design = m.renderer.Design(repo, n).Name
+		}
+		if n.IsDir {
+			in.Children = map[buildings.Archetype]int{}
+			for _, ch := range n.Children {
+				in.Children[m.renderer.Archetype(repo, ch)]++
+			}
+		}
+	})
+	return in
+}
+
+// searchKey edits the search prompt; matches update as the user types
+func (m Model) searchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		if m.stop != nil {
+			_ = m.stop()
+		}
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.searching = false
+		m.jump(0)
+	case tea.KeyEsc:
+		m.searching, m.query, m.matches = false, "", nil
+	default:
+		if q, ok := editText(m.query, msg); ok {
+			m.query = q
+			m.withRepo(func(repo *domain.RepoState) {
+				m.matches, m.matchIdx = search.Match(repo, m.query), 0
+			})
+		}
+	}
+	m.updateSearch()
+	return m, nil
+}
+
+// editText applies a typing or backspace key to s; ok is false for other keys
+func editText(s string, msg tea.KeyMsg) (string, bool) {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if r := []rune(s); len(r) > 0 {
+			return string(r[:len(r)-1]), true
+		}
+		return s, true
+	case tea.KeySpace:
+		// Bubble Tea v1 types space as its own key
+		return s + " ", true
+	case tea.KeyRunes:
+		return s + string(msg.Runes), true
+	}
+	return s, false
+}
+
+// updateSearch redraws the current frame with the prompt and highlights
+func (m *Model) updateSearch() {
+	m.scene.Prompt = m.prompt()
+	m.scene.Highlight(m.matches)
+}
+
+// jump centers the view on match i, wrapping around the results
+func (m *Model) jump(i int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.matchIdx = (i%len(m.matches) + len(m.matches)) % len(m.matches)
+	m.selected = m.matches[m.matchIdx]
+	if slot, ok := m.scene.Locate(m.selected); ok {
+		m.scene.Center(slot)
+		m.viewX, m.viewY, m.viewSet = m.scene.ViewportX, m.scene.ViewportY, true
+	}
+	m.scene.Select(m.selected)
+	m.scene.Prompt = m.prompt()
+}
+
+// prompt is the search or filter line shown in place of the key help
+func (m Model) prompt() string {
+	switch {
+	case m.filterEditing && m.filterErr != "":
+		return fmt.Sprintf("filter: %s█  error: %s", m.filterInput, m.filterErr)
+	case m.filterEditing:
+		return fmt.Sprintf("filter: %s█  [dim|hide] recent:<min> | archetype:<name> | prefix:<path> | git:<state|dirty>  (enter) apply  (esc) cancel", m.filterInput)
+	case m.notice != "":
+		return m.notice
+	case m.searching:
+		return fmt.Sprintf("/%s█  %d matches  (enter) jump  (esc) cancel", m.query, len(m.matches))
+	case m.query == "":
+		return m.lapse.ticker() + m.replay.ticker()
+	case len(m.matches) == 0:
+		return fmt.Sprintf("search %q: no matches  (esc) clear", m.query)
+	default:
+		rel, err := filepath.Rel(m.root, m.matches[m.matchIdx])
+		if err != nil {
+			rel = m.matches[m.matchIdx]
+		}
+		return fmt.Sprintf("search %q: %d/%d %s  (n) next  (N) prev  (esc) clear", m.query, m.matchIdx+1, len(m.matches), filepath.ToSlash(rel))
+	}
+}
+
+// selectNext moves the selection cursor to the next (dir 1) or previous
+// (dir -1) house in layout order, bringing it into view
+func (m *Model) selectNext(dir int) {
+	var houses []layout.Slot
+	cur := -1
+	for _, sl := range m.scene.Slots {
+		if sl.Kind != layout.SlotBuilding {
+			continue
+		}
+		if sl.Path == m.selected {
+			cur = len(houses)
+		}
+		houses = append(houses, sl)
+	}
+	if len(houses) == 0 {
+		return
+	}
+	i := 0
+	switch {
+	case cur >= 0:
+		i = (cur + dir + len(houses)) % len(houses)
+	case dir < 0:
+		i = len(houses) - 1
+	}
+	m.selected = houses[i].Path
+	if !m.scene.Visible(houses[i]) {
+		m.scene.Center(houses[i])
+		m.viewX, m.viewY, m.viewSet = m.scene.ViewportX, m.scene.ViewportY, true
+	}
+	m.scene.Select(m.selected)
+}
+
+// Terminal cells moved per pan key press
+const (
+	panStepX = 4
+	panStepY = 2
+)
+
+// pan moves the view by dx, dy terminal cells at the current zoom
+func (m *Model) pan(dx, dy int) {
+	kx, ky := scene.ZoomScale(m.zoom)
+	m.panCells(dx*kx, dy*ky)
+}
+
+// panCells moves the view by dx, dy map cells and redraws the current frame,
+// so panning also works while paused
+func (m *Model) panCells(dx, dy int) {
+	m.scene.SetViewport(m.scene.ViewportX+dx, m.scene.ViewportY+dy)
+	m.viewX, m.viewY, m.viewSet = m.scene.ViewportX, m.scene.ViewportY, true
+}
+
+// setZoom changes the zoom level around the center of the view
+func (m *Model) setZoom(zoom int) {
+	m.scene.SetZoom(zoom)
+	m.zoom = max(0, min(scene.MaxZoom, zoom))
+	m.viewX, m.viewY = m.scene.ViewportX, m.scene.ViewportY
+	m.viewSet = m.scene.VirtualMap != nil
 }
 
 func tick(fps int) tea.Cmd {
@@ -161,6 +565,61 @@ func waitEvents(ch chan watch.EventOut) tea.Cmd {
 	return func() tea.Msg { return eventsMsg(<-ch) }
 }
 
+// reconcile schedules a full rescan after the configured interval.
+func reconcile(root string, cfg config.Config) tea.Cmd {
+	if cfg.Watch.ReconcileMS <= 0 {
+		return nil
+	}
+	d := time.Duration(cfg.Watch.ReconcileMS) * time.Millisecond
+	return func() tea.Msg {
+		time.Sleep(d)
+		repo, err := scan.BuildTree(root, cfg)
+		if err != nil {
+			return reconcileMsg{}
+		}
+		return reconcileMsg{repo: repo}
+	}
+}
+
+// readGit reads git status after delay; it is disabled in village.yml with
+// git.enabled: false
+func readGit(root string, cfg config.Config, delay time.Duration) tea.Cmd {
+	if !cfg.Git.Enabled {
+		return nil
+	}
+	return func() tea.Msg {
+		time.Sleep(delay)
+		states, err := git.Read(root)
+		return gitMsg{states: states, err: err}
+	}
+}
+
+// pollLogs reads what the lantern logs gained after delay. The tailer is only
+// used from the one poll in flight.
+func (m Model) pollLogs(delay time.Duration) tea.Cmd {
+	if m.tailer == nil {
+		return nil
+	}
+	var lanterns []string
+	for path, n := range m.repo.Index {
+		if !n.IsDir && m.renderer.Archetype(m.repo, n) == buildings.Lantern {
+			lanterns = append(lanterns, path)
+		}
+	}
+	t := m.tailer
+	return func() tea.Msg {
+		time.Sleep(delay)
+		t.Sync(lanterns)
+		return logsMsg(t.Poll(time.Now()))
+	}
+}
+
+// annotate reapplies the last git and log readings after the tree changed
+func (m *Model) annotate() {
+	m.repo.ApplyGit(m.gitStates)
+	m.repo.ApplyLogs(m.logActivity)
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -168,16 +627,11 @@ func max(a, b int) int {
 	return b
 }
 
-// preserveAnimationStates copies active animation states from old repo to new repo
-func (m *Model) preserveAnimationStates(newRepo *domain.RepoState) {
-	if m.repo == nil {
-		return
-	}
-	for path, oldNode := range m.repo.Index {
-		if newNode, exists := newRepo.Index[path]; exists && oldNode.IsStateActive() {
-			newNode.State = oldNode.State
-			newNode.StateTime = oldNode.StateTime
-			newNode.StateExpiry = oldNode.StateExpiry
-		}
-	}
+// reconcileWith patches the tree toward scanned, a full rescan, keeping what
+// the watcher applied while it ran
+func (m *Model) reconcileWith(scanned *domain.RepoState) {
+	// the scan read every ignore file afresh; restat with the same rules
+	m.stater.Invalidate()
+	m.restat(m.repo.Diff(scanned))
+	m.annotate()
 }
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/layout"
+)
+
+// TestKeysAndMouse drives the model with the messages Bubble Tea v1 sends
+func TestKeysAndMouse(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "README.md", "village notes.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.Default()
+	cfg.Git.Enabled, cfg.Logs.Enabled = false, false
+	m, err := NewModel(dir, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.stop()
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	update(tickMsg(time.Now()))
+
+	// a click is a press and a release in place
+	x, y, found := building(m)
+	if !found {
+		t.Fatalf("no building on screen")
+	}
+	update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionRelease, Button: tea.MouseButtonNone})
+	if m.inspected == nil || m.inspected.Kind != layout.SlotBuilding || m.selected != m.inspected.Path {
+		t.Fatalf("click at %d,%d inspected %+v", x, y, m.inspected)
+	}
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.inspected != nil {
+		t.Fatalf("escape left the inspector open")
+	}
+
+	// a press, motion and release drags the map instead
+	update(tea.MouseMsg{X: 50, Y: 20, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	update(tea.MouseMsg{X: 40, Y: 20, Action: tea.MouseActionMotion, Button: tea.MouseButtonLeft})
+	update(tea.MouseMsg{X: 40, Y: 20, Action: tea.MouseActionRelease, Button: tea.MouseButtonNone})
+	if m.dragging || !m.dragMoved || m.inspected != nil {
+		t.Fatalf("drag: dragging %v, moved %v, inspected %+v", m.dragging, m.dragMoved, m.inspected)
+	}
+
+	update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if m.zoom != 1 {
+		t.Fatalf("wheel down: zoom %d, want 1", m.zoom)
+	}
+	update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	if m.zoom != 0 {
+		t.Fatalf("wheel up: zoom %d, want 0", m.zoom)
+	}
+
+	// search typing, with space as its own key type
+	update(key("/"))
+	for _, msg := range []tea.KeyMsg{key("v"), key("i"), {Type: tea.KeySpace, Runes: []rune{' '}}, key("n"), key("x"), {Type: tea.KeyBackspace}} {
+		update(msg)
+	}
+	if !m.searching || m.query != "vi n" {
+		t.Fatalf("search prompt = %q (searching %v), want %q", m.query, m.searching, "vi n")
+	}
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searching || len(m.matches) == 0 {
+		t.Fatalf("enter should close the prompt on the matches, got %v", m.matches)
+	}
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	update(key("p"))
+	update(key("?"))
+	if !m.paused || !m.showHelp {
+		t.Fatalf("p and ? should pause and show help")
+	}
+	// time-lapse plays on " "
+	if got := (tea.KeyMsg{Type: tea.KeySpace}).String(); got != " " {
+		t.Fatalf("space reads as %q", got)
+	}
+}
+
+// building finds a screen cell showing a building
+func building(m Model) (x, y int, ok bool) {
+	for y := 0; y < m.scene.H; y++ {
+		for x := 0; x < m.scene.W; x++ {
+			if slot, ok := m.scene.SlotAt(x, y); ok && slot.Kind == layout.SlotBuilding {
+				return x, y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
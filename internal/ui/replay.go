@@ -0,0 +1,223 @@
+// internal/ui/replay.go
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/recording"
+	"example.com/village-watch/internal/watch"
+)
+
+// Replay speed bounds, as multiples of the recorded pace
+const (
+	minReplaySpeed = 0.25
+	maxReplaySpeed = 64
+)
+
+// replayState drives a --replay of a recorded session
+type replayState struct {
+	session *recording.Session
+	next    int           // batch to apply next
+	clock   time.Duration // position in the recording
+	speed   float64
+	playing bool
+	last    time.Time // when the clock last moved
+	err     string
+}
+
+// NewRecordingModel watches root like NewModel and writes every watcher
+// batch to the session file name, after the tree it starts from
+func NewRecordingModel(root string, cfg config.Config, name string) (Model, error) {
+	m, err := NewModel(root, cfg)
+	if err != nil {
+		return Model{}, err
+	}
+	rec, err := recording.Create(name, m.repo)
+	if err != nil {
+		_ = m.stop()
+		return Model{}, fmt.Errorf("record: %w", err)
+	}
+	stop := m.stop
+	m.recorder = rec
+	m.stop = func() error { return errors.Join(stop(), rec.Close()) }
+	return m, nil
+}
+
+// NewReplayModel plays a recorded session at speed times its pace instead of
+// watching a directory. Designs and themes come from the configuration found
+// in root.
+func NewReplayModel(root string, cfg config.Config, session *recording.Session, speed float64) (Model, error) {
+	m, err := newModel(root, cfg)
+	if err != nil {
+		return Model{}, err
+	}
+	if m.repo, err = session.Tree.Repo(); err != nil {
+		return Model{}, err
+	}
+	m.root = m.repo.RootPath
+	m.replay = &replayState{session: session, speed: math.Max(minReplaySpeed, math.Min(maxReplaySpeed, speed)), playing: true}
+	return m, nil
+}
+
+// observe applies a watcher batch, recording it when asked to
+func (m *Model) observe(batch watch.EventOut) {
+	m.stater.Observe(batch.Events)
+	stat := m.stater.Stat
+	if m.recorder != nil {
+		stat = m.recorder.Stat(stat)
+	}
+	m.repo.Observe(batch.Events, stat)
+	if m.recorder == nil {
+		return
+	}
+	if err := m.recorder.Record(batch.Events); err != nil {
+		m.notice = "recording: " + err.Error()
+	}
+}
+
+// restat reads paths again outside the watcher's batches, as a rescan or the
+// return from the editor does, recording them when asked to
+func (m *Model) restat(paths []string) {
+	stat := m.stater.Stat
+	if m.recorder != nil {
+		stat = m.recorder.Stat(stat)
+	}
+	m.repo.Reconcile(paths, stat)
+	if m.recorder == nil || len(paths) == 0 {
+		return
+	}
+	if err := m.recorder.Rescan(paths); err != nil {
+		m.notice = "recording: " + err.Error()
+	}
+}
+
+// replayKey handles the playback keys; ok is false for other keys
+func (m Model) replayKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	r := m.replay
+	switch msg.String() {
+	case " ":
+		if !r.playing && r.next >= len(r.session.Batches) {
+			// play again from the start
+			m.replayFrom(0)
+		}
+		r.playing = !r.playing
+	case "]":
+		r.speed = math.Min(r.speed*2, maxReplaySpeed)
+	case "[":
+		r.speed = math.Max(r.speed/2, minReplaySpeed)
+	case ".":
+		r.playing = false
+		if r.next < len(r.session.Batches) {
+			m.applyBatch()
+		}
+	case ",":
+		r.playing = false
+		m.replayFrom(r.next - 1)
+	case "r":
+		m.replayFrom(0)
+	default:
+		return m, nil, false
+	}
+	m.scene.Prompt = m.prompt()
+	return m, nil, true
+}
+
+// replayTo moves the clock of a playing replay to now and applies the
+// batches that came due
+func (m *Model) replayTo(now time.Time) {
+	r := m.replay
+	if r == nil {
+		return
+	}
+	if r.playing && !r.last.IsZero() {
+		r.clock += time.Duration(float64(now.Sub(r.last)) * r.speed)
+	}
+	r.last = now
+	for r.next < len(r.session.Batches) && r.session.Batches[r.next].At <= r.clock {
+		m.applyBatch()
+	}
+	if r.playing && r.next >= len(r.session.Batches) {
+		r.playing = false
+		m.scene.Prompt = m.prompt()
+	}
+}
+
+// applyBatch applies the next batch as the watcher delivered it, with the
+// restats recorded alongside
+func (m *Model) applyBatch() {
+	r := m.replay
+	b := r.session.Batches[r.next]
+	b.Apply(m.repo)
+	r.next++
+	if b.At > r.clock {
+		r.clock = b.At
+	}
+}
+
+// replayFrom rebuilds the recorded tree and applies the first n batches at
+// once
+func (m *Model) replayFrom(n int) {
+	r := m.replay
+	repo, err := r.session.Tree.Repo()
+	if err != nil {
+		r.err, r.playing = err.Error(), false
+		return
+	}
+	m.repo, r.next, r.clock, r.err = repo, 0, 0, ""
+	for r.next < n {
+		m.applyBatch()
+	}
+}
+
+// status is the playback state prefixed to the status bar
+func (r *replayState) status() string {
+	if r == nil {
+		return ""
+	}
+	mark := "||"
+	if r.playing {
+		mark = ">"
+	}
+	return fmt.Sprintf("[REPLAY %d/%d %s %gx %s/%s] ", r.next, len(r.session.Batches), mark, r.speed,
+		clockTime(r.clock), clockTime(r.session.Duration()))
+}
+
+// ticker describes the last batch applied in place of the key help
+func (r *replayState) ticker() string {
+	const keys = "   (space) play  ([ ]) speed  (, .) step  (r) restart"
+	switch {
+	case r == nil:
+		return ""
+	case r.err != "":
+		return "replay: " + r.err
+	case r.next == 0:
+		return "replay: start of the recording" + keys
+	}
+	b := r.session.Batches[r.next-1]
+	if len(b.Events) == 0 {
+		return fmt.Sprintf("replay: rescan restating %d paths", len(b.Rescan)) + keys
+	}
+	e := b.Events[0]
+	rel, err := filepath.Rel(r.session.Tree.Root, e.Path)
+	if err != nil {
+		rel = e.Path
+	}
+	more := ""
+	if len(b.Events) > 1 {
+		more = fmt.Sprintf(" and %d more", len(b.Events)-1)
+	}
+	return fmt.Sprintf("%s %s %s%s", e.When.Format("15:04:05"), e.Kind, filepath.ToSlash(rel), more) + keys
+}
+
+// clockTime formats a position in the recording as m:ss
+func clockTime(d time.Duration) string {
+	s := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
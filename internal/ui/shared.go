@@ -0,0 +1,57 @@
+// internal/ui/shared.go
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	lg "github.com/charmbracelet/lipgloss"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/live"
+)
+
+// NewSharedModel shows a village that other sessions follow as well. The
+// view, theme, search and filter are this session's own; the tree and its
+// watcher belong to v. Styles are drawn by styles, the renderer of the
+// session's terminal, or by the default one when nil.
+func NewSharedModel(v *live.Village, cfg config.Config, styles *lg.Renderer) (Model, error) {
+	m, err := newModel(v.Root(), cfg)
+	if err != nil {
+		return Model{}, err
+	}
+	m.village = v
+	if styles != nil {
+		m.themes = m.themes.WithRenderer(styles)
+	}
+	return m, nil
+}
+
+// frame advances the animations and calls f with the tree. A shared tree is
+// only lent for the call.
+func (m *Model) frame(f func(repo *domain.RepoState)) {
+	if m.village != nil {
+		m.village.Frame(f)
+		return
+	}
+	m.repo.PruneDemolished()
+	m.repo.UpdateStates()
+	f(m.repo)
+}
+
+// withRepo calls f with the tree as it is
+func (m Model) withRepo(f func(repo *domain.RepoState)) {
+	if m.village != nil {
+		m.village.Read(f)
+		return
+	}
+	f(m.repo)
+}
+
+// rescan has the shared village rescan its directory for every session
+func (m Model) rescan() tea.Cmd {
+	v := m.village
+	return func() tea.Msg {
+		v.Rescan()
+		return nil
+	}
+}
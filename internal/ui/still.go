@@ -0,0 +1,20 @@
+// internal/ui/still.go
+package ui
+
+import (
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/scene"
+)
+
+// NewStillModel shows a tree that nothing updates, such as one loaded from a
+// snapshot, on a map sized by bounds. Designs and themes come from the
+// configuration found in root.
+func NewStillModel(root string, cfg config.Config, repo *domain.RepoState, bounds scene.MapBounds) (Model, error) {
+	m, err := newModel(root, cfg)
+	if err != nil {
+		return Model{}, err
+	}
+	m.root, m.repo, m.still, m.bounds = repo.RootPath, repo, true, &bounds
+	return m, nil
+}
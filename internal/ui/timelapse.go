@@ -0,0 +1,193 @@
+// internal/ui/timelapse.go
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/git"
+	"example.com/village-watch/internal/timelapse"
+)
+
+// Playback speeds in commits per second
+var timelapseSpeeds = []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32}
+
+const defaultTimelapseSpeed = 3
+
+// Animation length bounds; within them an animation lasts one commit
+const (
+	minTimelapseAnim = 250 * time.Millisecond
+	maxTimelapseAnim = 2 * time.Second
+)
+
+// timelapseState drives a --timelapse replay of the git history
+type timelapseState struct {
+	history  *timelapse.History
+	pos      int                    // commit on screen, -1 until the first one loads
+	stats    []domain.ActivityStats // the counters as each commit up to pos left them
+	commit   git.Commit
+	playing  bool
+	speed    int // index into timelapseSpeeds
+	loading  bool
+	lastStep time.Time
+	err      string
+}
+
+type frameMsg struct {
+	frame timelapse.Frame
+	err   error
+}
+
+// NewTimelapseModel replays the history of the repository at root instead of
+// watching it
+func NewTimelapseModel(root string, cfg config.Config) (Model, error) {
+	m, err := newModel(root, cfg)
+	if err != nil {
+		return Model{}, err
+	}
+	history, err := timelapse.Open(root, cfg)
+	if err != nil {
+		return Model{}, fmt.Errorf("timelapse: %w", err)
+	}
+	m.repo = domain.NewRepo(root)
+	m.repo.Root = &domain.FileNode{Path: root, Name: filepath.Base(root), IsDir: true}
+	m.repo.Upsert(m.repo.Root)
+	m.lapse = &timelapseState{history: history, pos: -1, playing: true, speed: defaultTimelapseSpeed}
+	return m, nil
+}
+
+// interval is the time each commit stays on screen
+func (l *timelapseState) interval() time.Duration {
+	return time.Duration(float64(time.Second) / timelapseSpeeds[l.speed])
+}
+
+// timelapseKey handles the playback keys; ok is false for other keys
+func (m Model) timelapseKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	l := m.lapse
+	switch msg.String() {
+	case " ":
+		if !l.playing && l.pos+1 >= l.history.Len() {
+			// play again from the start
+			return m, m.loadCommit(0), true
+		}
+		l.playing = !l.playing
+	case "]":
+		l.speed = min(l.speed+1, len(timelapseSpeeds)-1)
+	case "[":
+		l.speed = max(l.speed-1, 0)
+	case ".":
+		l.playing = false
+		return m, m.loadCommit(l.pos + 1), true
+	case ",":
+		l.playing = false
+		return m, m.loadCommitBack(l.pos - 1), true
+	case "r":
+		return m, m.loadCommit(0), true
+	default:
+		return m, nil, false
+	}
+	m.scene.Prompt = m.prompt()
+	return m, nil, true
+}
+
+// loadCommit reads commit i in the background
+func (m *Model) loadCommit(i int) tea.Cmd {
+	return m.load(i, m.lapse.history.Load)
+}
+
+// loadCommitBack reads commit i in the background, stepping back from the
+// commit on screen
+func (m *Model) loadCommitBack(i int) tea.Cmd {
+	return m.load(i, m.lapse.history.LoadBack)
+}
+
+func (m *Model) load(i int, load func(int) (timelapse.Frame, error)) tea.Cmd {
+	l := m.lapse
+	if l.loading || i < 0 || i >= l.history.Len() {
+		return nil
+	}
+	l.loading = true
+	return func() tea.Msg {
+		f, err := load(i)
+		return frameMsg{frame: f, err: err}
+	}
+}
+
+// advance loads the next commit once the current one has been shown long
+// enough, stopping at HEAD
+func (m *Model) advance(now time.Time) tea.Cmd {
+	l := m.lapse
+	if l == nil || !l.playing || l.loading || now.Sub(l.lastStep) < l.interval() {
+		return nil
+	}
+	if l.pos+1 >= l.history.Len() {
+		l.playing = false
+		m.scene.Prompt = m.prompt()
+		return nil
+	}
+	return m.loadCommit(l.pos + 1)
+}
+
+// showFrame puts a loaded commit on screen and starts its animations
+func (m *Model) showFrame(msg frameMsg) {
+	l := m.lapse
+	l.loading = false
+	if msg.err != nil {
+		l.err, l.playing = msg.err.Error(), false
+		m.scene.Prompt = m.prompt()
+		return
+	}
+	if msg.frame.Index == 0 && !msg.frame.Back {
+		// starting over: counts begin again
+		m.repo.Stats, m.repo.History = domain.ActivityStats{}, nil
+	}
+	anim := l.interval()
+	if anim < minTimelapseAnim {
+		anim = minTimelapseAnim
+	} else if anim > maxTimelapseAnim {
+		anim = maxTimelapseAnim
+	}
+	msg.frame.Continue(m.repo, anim)
+	m.repo = msg.frame.Repo
+	if msg.frame.Back && msg.frame.Index < len(l.stats) {
+		// the counters go back to what this commit left them at
+		m.repo.Stats = l.stats[msg.frame.Index]
+	}
+	// every commit before the one on screen was shown on the way to it
+	l.stats = append(l.stats[:min(len(l.stats), msg.frame.Index)], m.repo.Stats)
+	l.pos, l.commit, l.err = msg.frame.Index, msg.frame.Commit, ""
+	l.lastStep = time.Now()
+	m.scene.Prompt = m.prompt()
+}
+
+// status is the playback state prefixed to the status bar
+func (l *timelapseState) status() string {
+	if l == nil {
+		return ""
+	}
+	mark := "||"
+	if l.playing {
+		mark = ">"
+	}
+	return fmt.Sprintf("[TIMELAPSE %d/%d %s %g/s] ", l.pos+1, l.history.Len(), mark, timelapseSpeeds[l.speed])
+}
+
+// ticker shows the commit on screen in place of the key help
+func (l *timelapseState) ticker() string {
+	switch {
+	case l == nil:
+		return ""
+	case l.err != "":
+		return "timelapse: " + l.err
+	case l.pos < 0:
+		return "timelapse: reading history…"
+	}
+	c := l.commit
+	return fmt.Sprintf("%s %s  %s  %s   (space) play  ([ ]) speed  (, .) step  (r) restart",
+		c.Short(), c.When.Format("2006-01-02 15:04"), c.Author, c.Subject)
+}
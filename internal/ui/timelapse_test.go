@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+)
+
+func TestTimelapseStepsBackToEarlierCounters(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		git("add", ".")
+		git("commit", "-q", "-m", "step")
+	}
+	git("init", "-q")
+	commit(map[string]string{"a.go": "package a\n"})
+	commit(map[string]string{"a.go": "package a // changed\n", "b.go": "package a\n"})
+	commit(map[string]string{"c.go": "package a\n"})
+
+	cfg := config.Default()
+	cfg.Git.Enabled, cfg.Logs.Enabled = false, false
+	m, err := NewTimelapseModel(dir, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// run the frame loads the keys start, as Bubble Tea would
+	update := func(msg tea.Msg) {
+		t.Helper()
+		next, cmd := m.Update(msg)
+		m = next.(Model)
+		if cmd == nil {
+			return
+		}
+		if frame, ok := cmd().(frameMsg); ok {
+			next, _ = m.Update(frame)
+			m = next.(Model)
+		}
+	}
+	step := func(key string, pos int, want domain.ActivityStats) {
+		t.Helper()
+		update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if m.lapse.pos != pos || m.repo.Stats != want {
+			t.Fatalf("after %q: commit %d with %+v, want commit %d with %+v", key, m.lapse.pos, m.repo.Stats, pos, want)
+		}
+	}
+	state := func(name string) domain.FileState {
+		if n := m.repo.Index[filepath.Join(dir, name)]; n != nil {
+			return n.State
+		}
+		return -1
+	}
+
+	update(m.loadCommit(0)())
+	step(".", 1, domain.ActivityStats{NewFiles: 2, Modified: 1})
+	step(".", 2, domain.ActivityStats{NewFiles: 3, Modified: 1})
+	step(",", 1, domain.ActivityStats{NewFiles: 2, Modified: 1})
+	if state("c.go") != domain.StateDeleted {
+		t.Fatalf("stepping back should demolish c.go, state %v", state("c.go"))
+	}
+	step(",", 0, domain.ActivityStats{NewFiles: 1})
+	if state("b.go") != domain.StateDeleted || state("a.go") != domain.StateModified {
+		t.Fatalf("stepping back to the first commit: b.go %v, a.go %v", state("b.go"), state("a.go"))
+	}
+	if h := m.repo.History[filepath.Join(dir, "a.go")]; len(h) != 1 || h[0].Kind != domain.Create {
+		t.Fatalf("history of a.go = %+v, want its creation only", h)
+	}
+	step(".", 1, domain.ActivityStats{NewFiles: 2, Modified: 1})
+}
@@ -3,6 +3,7 @@ package watch
 
 import (
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"example.com/village-watch/internal/config"
 	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/ignore"
 )
 
 type EventOut struct {
@@ -25,21 +27,12 @@ func Start(root string, cfg config.Config) (chan EventOut, func() error, error)
 	out := make(chan EventOut, 4)
 	debounce := time.Duration(cfg.Watch.DebounceMS) * time.Millisecond
 
-	// walk and add watchers for dirs
-	addDir := func(path string) error {
-		return w.Add(path)
-	}
+	d := &dirs{w: w, matcher: ignore.New(root, cfg.Watch.Ignore), watched: make(map[string]bool)}
 	// initial: watch root + subdirs
-	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() && !ignored(p, cfg) {
-			_ = addDir(p)
-		}
-		return nil
-	})
+	d.addTree(root, nil)
 	go func() {
+		// closed here rather than by stop, which could race a flush
+		defer close(out)
 		buf := make([]domain.FsEvent, 0, 64)
 		var last time.Time
 		flush := func() {
@@ -53,17 +46,35 @@ func Start(root string, cfg config.Config) (chan EventOut, func() error, error)
 			select {
 			case ev, ok := <-w.Events:
 				if !ok {
+					// the watcher was closed; hand over what is still held
+					flush()
 					return
 				}
 				k := mapKind(ev)
 				if k == -1 {
 					continue
 				}
-				if ignored(ev.Name, cfg) {
+				if ignore.IsIgnoreFile(filepath.Base(ev.Name)) {
+					d.matcher.Invalidate()
+				}
+				if d.ignored(ev.Name) {
 					continue
 				}
-				buf = append(buf, domain.FsEvent{Path: ev.Name, Kind: k, When: time.Now()})
-				last = time.Now()
+				now := time.Now()
+				buf = append(buf, domain.FsEvent{Path: ev.Name, Kind: k, When: now})
+				switch k {
+				case domain.Create:
+					// New directories get watched recursively; anything created
+					// inside them before the watch attached is synthesized
+					if info, err := os.Lstat(ev.Name); err == nil && info.IsDir() {
+						d.addTree(ev.Name, func(p string) {
+							buf = append(buf, domain.FsEvent{Path: p, Kind: domain.Create, When: now})
+						})
+					}
+				case domain.Remove, domain.Rename:
+					d.removeTree(ev.Name)
+				}
+				last = now
 			case <-time.After(debounce):
 				if time.Since(last) >= debounce {
 					flush()
@@ -73,10 +84,64 @@ func Start(root string, cfg config.Config) (chan EventOut, func() error, error)
 			}
 		}
 	}()
-	stop := func() error { defer close(out); return w.Close() }
+	stop := func() error { return w.Close() }
 	return out, stop, nil
 }
 
+// dirs tracks the directories with an active fsnotify watch; it is only
+// touched by the initial walk and then by the event goroutine
+type dirs struct {
+	w       *fsnotify.Watcher
+	matcher *ignore.Matcher
+	watched map[string]bool
+}
+
+// ignored checks a path against the shared engine; removed paths can no
+// longer be statted, so only previously watched ones count as directories
+func (d *dirs) ignored(p string) bool {
+	isDir := d.watched[p]
+	if info, err := os.Lstat(p); err == nil {
+		isDir = info.IsDir()
+	}
+	return d.matcher.Match(p, isDir)
+}
+
+// addTree watches dir and every non-ignored directory below it, calling
+// found for each entry discovered underneath
+func (d *dirs) addTree(dir string, found func(p string)) {
+	_ = filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.matcher.Match(p, e.IsDir()) {
+			if e.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if p != dir && found != nil {
+			found(p)
+		}
+		if e.IsDir() && !d.watched[p] {
+			if d.w.Add(p) == nil {
+				d.watched[p] = true
+			}
+		}
+		return nil
+	})
+}
+
+// removeTree drops watches for dir and everything that was watched below it
+func (d *dirs) removeTree(dir string) {
+	prefix := dir + string(filepath.Separator)
+	for p := range d.watched {
+		if p == dir || strings.HasPrefix(p, prefix) {
+			_ = d.w.Remove(p)
+			delete(d.watched, p)
+		}
+	}
+}
+
 func mapKind(ev fsnotify.Event) domain.EventKind {
 	if ev.Op&fsnotify.Create == fsnotify.Create {
 		return domain.Create
@@ -92,16 +157,3 @@ func mapKind(ev fsnotify.Event) domain.EventKind {
 	}
 	return -1
 }
-
-func ignored(path string, cfg config.Config) bool {
-	base := filepath.Base(path)
-	for _, g := range cfg.Watch.Ignore {
-		if m, _ := filepath.Match(g, base); m {
-			return true
-		}
-		if strings.HasSuffix(g, "/") && strings.Contains(path, g) {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,129 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"example.com/village-watch/internal/config"
+	"example.com/village-watch/internal/domain"
+	"example.com/village-watch/internal/ignore"
+)
+
+func TestNewDirectoriesAreWatchedRecursively(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.Default()
+	cfg.Watch.DebounceMS = 20
+	out, stop, err := Start(root, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// the tree appears faster than a watch can attach to each level
+	if err := os.MkdirAll(filepath.Join(root, "a", "b", "c"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "b", "c", "f.go"), []byte("package c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{}
+	for _, rel := range []string{"a", "a/b", "a/b/c", "a/b/c/f.go"} {
+		want[filepath.Join(root, filepath.FromSlash(rel))] = true
+	}
+	timeout := time.After(5 * time.Second)
+	for len(want) > 0 {
+		select {
+		case batch := <-out:
+			for _, e := range batch.Events {
+				if e.Kind == domain.Create {
+					delete(want, e.Path)
+				}
+			}
+		case <-timeout:
+			t.Fatalf("no create events for %v", want)
+		}
+	}
+}
+
+func TestStopFlushesPendingEvents(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.Default()
+	cfg.Watch.DebounceMS = 60_000
+	out, stop, err := Start(root, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := filepath.Join(root, "f.go")
+	if err := os.WriteFile(f, []byte("package f\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// let the event reach the buffer, well short of the debounce
+	time.Sleep(200 * time.Millisecond)
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for batch := range out {
+		for _, e := range batch.Events {
+			got = append(got, e.Path)
+		}
+	}
+	if len(got) == 0 || got[0] != f {
+		t.Fatalf("events after stop = %v, want %s", got, f)
+	}
+}
+
+func TestRemoveTreeDropsWatches(t *testing.T) {
+	root := t.TempDir()
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	d := &dirs{w: w, matcher: ignore.New(root, []string{"node_modules/"}), watched: map[string]bool{}}
+	d.addTree(root, nil)
+
+	for _, dir := range []string{"a/b/c", "a/node_modules/x"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "b", "c", "f.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	d.addTree(filepath.Join(root, "a"), func(p string) {
+		rel, _ := filepath.Rel(root, p)
+		found = append(found, filepath.ToSlash(rel))
+	})
+	if got := strings.Join(found, " "); got != "a/b a/b/c a/b/c/f.go" {
+		t.Fatalf("found %s", got)
+	}
+	if got := watchedRel(d, root); got != ". a a/b a/b/c" {
+		t.Fatalf("watched %s", got)
+	}
+
+	d.removeTree(filepath.Join(root, "a"))
+	if got := watchedRel(d, root); got != "." {
+		t.Fatalf("after removing a, watched %s", got)
+	}
+	if got := w.WatchList(); len(got) != 1 || got[0] != root {
+		t.Fatalf("fsnotify still watches %v", got)
+	}
+}
+
+func watchedRel(d *dirs, root string) string {
+	var rels []string
+	for p := range d.watched {
+		rel, _ := filepath.Rel(root, p)
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	sort.Strings(rels)
+	return strings.Join(rels, " ")
+}
@@ -0,0 +1,9 @@
+// themes/themes.go
+package themes
+
+import "embed"
+
+// FS holds the bundled theme files, one *.yaml per theme
+//
+//go:embed *.yaml
+var FS embed.FS